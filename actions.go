@@ -7,17 +7,14 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/sahilm/fuzzy"
-
-	"github.com/dkaslovsky/nav/internal/sanitize"
 )
 
 func (m *model) Init() tea.Cmd {
 	// If indexing is already active (e.g., started via -t flag), return polling command
 	if m.searchIndexLoading && m.searchIndexChan != nil {
-		return m.pollSearchIndexCmd()
+		return tea.Batch(m.pollSearchIndexCmd(), m.refreshGitStatus(), m.startWatcher())
 	}
-	return nil
+	return tea.Batch(m.refreshGitStatus(), m.startWatcher())
 }
 
 func (m *model) View() string {
@@ -27,10 +24,24 @@ func (m *model) View() string {
 	}
 	if m.modeHelp {
 		view = commands()
-	} else if m.modeTree {
-		view = m.treeView()
+	} else if m.modeTrash {
+		view = m.listViewView(&m.trash.listView)
+	} else if m.modeBookmarks {
+		view = m.listViewView(&m.bookmarks.listView)
+	} else if m.modeFrecency {
+		view = m.listViewView(&m.frecency.listView)
+	} else if m.modeHistory {
+		view = m.listViewView(&m.historyView.listView)
+	} else if m.modeBasket {
+		view = m.listViewView(&m.basketView.listView)
+	} else if m.modeDualPane {
+		view = m.dualPaneView()
 	} else {
-		view = m.normalView()
+		view = m.paneView()
+	}
+
+	if len(m.tabs) > 1 {
+		view = m.tabBar() + "\n" + view
 	}
 
 	if m.hideStatusBar {
@@ -40,10 +51,60 @@ func (m *model) View() string {
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+
+	// The entry under the cursor may have changed, so refresh the preview once the view has been
+	// rendered and the cursor position resolved.
+	switch msg.(type) {
+	case tea.KeyMsg, tea.WindowSizeMsg, fuzzySearchResultMsg, searchIndexBatchMsg:
+		if m.modePreview && !m.modeExit {
+			cmd = tea.Batch(cmd, refreshPreviewCmd)
+		}
+	}
+
+	// The current directory may have moved to another repository, and rows listed from the search
+	// index cache may have been scrolled into view.
+	if !m.modeExit {
+		cmd = tea.Batch(cmd, m.refreshGitStatus())
+		m.refreshVisibleInfos()
+	}
+	return model, cmd
+}
+
+func (m *model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	esc := false
 
 	switch msg := msg.(type) {
 
+	case previewRefreshMsg:
+		return m, m.refreshPreview()
+
+	case watchMsg:
+		return m, tea.Batch(m.applyWatch(msg.dirs), m.pollWatchCmd(), m.indexingCmd())
+
+	case indexValidatedMsg:
+		// Ignore results for an index that has since been reloaded
+		if msg.generation != m.searchIndexGeneration {
+			return m, nil
+		}
+		return m, m.applyIndexValidation(msg.changed)
+
+	case gitStatusMsg:
+		// Ignore results for a repository that is no longer current. Failures, such as git not
+		// being installed, leave entries without annotations.
+		if msg.root == m.git.root {
+			m.git.status = msg.status
+		}
+		return m, nil
+
+	case previewMsg:
+		// Ignore stale results for entries no longer under the cursor
+		if m.preview != nil && m.preview.path == msg.path {
+			m.preview.lines = msg.lines
+			m.preview.loading = false
+		}
+		return m, nil
+
 	case fuzzySearchResultMsg:
 		// Ignore stale results from old workers
 		if msg.generation != m.searchWorkerGeneration {
@@ -76,15 +137,17 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Incremental fuzzy matching: only search new nodes, then merge results
+		// Incremental matching: only search new nodes, then merge results
 		if m.search != "" && len(msg.nodes) > 0 {
-			// Only fuzzy search the NEW names
-			newNames := m.searchIndexNames[startIdx:]
-			newMatches := fuzzy.Find(m.search, newNames)
+			// Only search the NEW names
+			root := m.treeSearchRootPath()
+			newMatches := findTreeMatches(
+				m.search, root, m.searchIndexNames[startIdx:], m.searchIndexNodes[startIdx:], m.modeSearchPaths,
+			)
 
 			// Adjust indices to be absolute (add startIdx offset)
 			for i := range newMatches {
-				newMatches[i].Index += startIdx
+				newMatches[i].index += startIdx
 			}
 
 			// Merge into pending matches (maintain score order)
@@ -103,11 +166,18 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.searchIndexChan = nil
 		// Clear pending matches since indexing is done
 		m.searchPendingMatches = nil
+		// Apply the changes to watched directories that arrived while indexing
+		var cmd tea.Cmd
+		if len(m.watchPending) != 0 {
+			cmd = m.refreshTreeDirs(m.watchPending)
+		}
+		// Check the directories listed from the on-disk cache for changes
+		cmd = tea.Batch(cmd, m.validateIndexCache())
 		// If we're in search mode and worker isn't running, start it now that we have an index
 		if m.modeSearch && m.modeTree && m.searchQueryChan == nil && len(m.searchIndexNodes) > 0 {
-			return m, m.startSearchWorker()
+			return m, tea.Batch(cmd, m.startSearchWorker())
 		}
-		return m, nil
+		return m, cmd
 
 	case tea.WindowSizeMsg:
 		if result := actionWindowResize(m, msg, esc); !result.noop {
@@ -115,6 +185,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
+		m.lastKey = msg.String()
 
 		// Remapped escape logic
 		if key.Matches(msg, m.esc.key) {
@@ -135,6 +206,42 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.modePrompt {
+			if result := actionModePrompt(m, msg, esc); !result.noop {
+				return m, result.cmd
+			}
+		}
+
+		if m.modeTrash {
+			if result := actionModeTrash(m, msg, esc); !result.noop {
+				return m, result.cmd
+			}
+		}
+
+		if m.modeBookmarks {
+			if result := actionModeBookmarks(m, msg, esc); !result.noop {
+				return m, result.cmd
+			}
+		}
+
+		if m.modeFrecency {
+			if result := actionModeFrecency(m, msg, esc); !result.noop {
+				return m, result.cmd
+			}
+		}
+
+		if m.modeHistory {
+			if result := actionModeHistory(m, msg, esc); !result.noop {
+				return m, result.cmd
+			}
+		}
+
+		if m.modeBasket {
+			if result := actionModeBasket(m, msg, esc); !result.noop {
+				return m, result.cmd
+			}
+		}
+
 		if m.modeHelp {
 			if result := actionModeHelp(m, msg, esc); !result.noop {
 				return m, result.cmd
@@ -195,14 +302,15 @@ func newActionResultNoop() actionResult {
 }
 
 func actionWindowResize(m *model, msg tea.WindowSizeMsg, esc bool) actionResult {
-	m.width = msg.Width
-	m.height = msg.Height
+	m.screenWidth = msg.Width
+	m.screenHeight = msg.Height
+	m.resize()
 	return newActionResult(nil)
 }
 
 func actionQuit(m *model, msg tea.KeyMsg, esc bool) actionResult {
 	if key.Matches(msg, keyQuit) {
-		m.setExitWithCode("", 2)
+		m.setExitWithCode(2)
 		return newActionResult(tea.Quit)
 	}
 
@@ -239,6 +347,7 @@ func actionModeSearch(m *model, msg tea.KeyMsg, esc bool) actionResult {
 			}
 			m.treeSearchStartNode = nil
 			m.searchMatchNodes = nil
+			m.searchHighlights = nil
 			m.search = ""                // Clear search to unfilter
 			m.searchPendingMatches = nil // Clear pending matches
 			m.stopSearchWorker()         // Stop background worker
@@ -267,15 +376,7 @@ func actionModeSearch(m *model, msg tea.KeyMsg, esc bool) actionResult {
 		if len(m.search) > 0 {
 			m.search = m.search[:len(m.search)-1]
 			if m.modeTree {
-				// Dispatch to background worker if active, otherwise rebuild synchronously
-				if m.searchQueryChan != nil {
-					select {
-					case m.searchQueryChan <- m.search:
-					default:
-					}
-					return newActionResult(m.pollSearchResultCmd())
-				}
-				m.rebuildVisibleNodes()
+				return newActionResult(m.updateTreeSearch())
 			}
 			return newActionResult(nil)
 		}
@@ -302,6 +403,35 @@ func actionModeSearch(m *model, msg tea.KeyMsg, esc bool) actionResult {
 		_, cmd := m.searchSelectAction()
 		return newActionResult(cmd)
 
+	case key.Matches(msg, keySearchStrategy):
+		// Tree mode always searches fuzzily.
+		if !m.modeTree {
+			m.searchStrategy = m.searchStrategy.next()
+		}
+		return newActionResult(nil)
+
+	case key.Matches(msg, keySearchPaths):
+		// Only tree mode searches below the current directory.
+		if !m.modeTree {
+			return newActionResult(nil)
+		}
+		m.modeSearchPaths = !m.modeSearchPaths
+		m.treeIdx = 0
+		m.scrollOffset = 0
+		if len(m.searchIndexNodes) == 0 {
+			m.rebuildVisibleNodes()
+			return newActionResult(nil)
+		}
+		// Restart the worker, which captures the mode, and rerun the search
+		cmd := m.startSearchWorker()
+		if m.search != "" {
+			select {
+			case m.searchQueryChan <- m.search:
+			default:
+			}
+		}
+		return newActionResult(cmd)
+
 	case key.Matches(msg, keyTab):
 		if m.displayed != 1 {
 			return newActionResult(nil)
@@ -310,6 +440,11 @@ func actionModeSearch(m *model, msg tea.KeyMsg, esc bool) actionResult {
 		return newActionResult(cmd)
 
 	case key.Matches(msg, keyFileSeparator):
+		if m.modeTree {
+			// Separators are part of search terms matched against relative paths in tree mode
+			m.search += keyString(keyFileSeparator)
+			return newActionResult(m.updateTreeSearch())
+		}
 		if m.displayed != 1 {
 			m.search += keyString(keyFileSeparator)
 			return newActionResult(nil)
@@ -326,15 +461,7 @@ func actionModeSearch(m *model, msg tea.KeyMsg, esc bool) actionResult {
 		// (On Unix, keyFileSeparator handles this, but this case handles it on other systems)
 		m.search += "/"
 		if m.modeTree {
-			// Dispatch to background worker if active, otherwise rebuild synchronously
-			if m.searchQueryChan != nil {
-				select {
-				case m.searchQueryChan <- m.search:
-				default:
-				}
-				return newActionResult(m.pollSearchResultCmd())
-			}
-			m.rebuildVisibleNodes()
+			return newActionResult(m.updateTreeSearch())
 		}
 		return newActionResult(nil)
 
@@ -342,15 +469,7 @@ func actionModeSearch(m *model, msg tea.KeyMsg, esc bool) actionResult {
 		if msg.Type == tea.KeyRunes || key.Matches(msg, keySpace) {
 			m.search += string(msg.Runes)
 			if m.modeTree {
-				// Dispatch to background worker if active, otherwise rebuild synchronously
-				if m.searchQueryChan != nil {
-					select {
-					case m.searchQueryChan <- m.search:
-					default:
-					}
-					return newActionResult(m.pollSearchResultCmd())
-				}
-				m.rebuildVisibleNodes()
+				return newActionResult(m.updateTreeSearch())
 			}
 			return newActionResult(nil)
 		}
@@ -447,13 +566,25 @@ func actionModeTree(m *model, msg tea.KeyMsg, esc bool) actionResult {
 		// treeCollapse may navigate to parent dir which restarts indexing
 		return newActionResult(m.indexingCmd())
 
+	case key.Matches(msg, keyToggleFlat):
+		m.toggleSearchFlat()
+		return newActionResult(m.indexingCmd())
+
 	case key.Matches(msg, keyRight):
+		if m.flatSearchResults() {
+			// Matches in the flat list have no children to show, so go to the match instead
+			node := m.selectedTreeNode()
+			if node == nil || node.entry == nil {
+				return newActionResult(m.indexingCmd())
+			}
+			return newActionResult(tea.Batch(m.jumpToEntry(node.fullPath), m.indexingCmd()))
+		}
 		cmd := m.treeExpand()
 		// Ensure polling continues even if treeExpand returns nil
 		return newActionResult(tea.Batch(cmd, m.indexingCmd()))
 
 	case key.Matches(msg, keyToggleExpand):
-		if !m.modeSearch {
+		if !m.modeSearch && !m.flatSearchResults() {
 			cmd := m.treeToggleExpand()
 			// Ensure polling continues even if treeToggleExpand returns nil
 			return newActionResult(tea.Batch(cmd, m.indexingCmd()))
@@ -469,6 +600,12 @@ func actionModeTree(m *model, msg tea.KeyMsg, esc bool) actionResult {
 			return newActionResult(m.indexingCmd())
 		}
 
+	case key.Matches(msg, keyMarkSubtree):
+		if !m.modeSearch {
+			m.toggleTreeMarkSubtree()
+			return newActionResult(m.indexingCmd())
+		}
+
 	case key.Matches(msg, keyBack):
 		// Backspace only active in search mode for tree
 		if m.modeSearch {
@@ -482,8 +619,20 @@ func actionModeTree(m *model, msg tea.KeyMsg, esc bool) actionResult {
 }
 
 func (m *model) treeSelectAction() actionResult {
+	// In the flat list, return the marked matches or the match under the cursor
+	if !m.modeSearch && m.flatSearchResults() && m.modeMarks {
+		paths, err := m.selectedPaths()
+		if err != nil {
+			m.setError(err, "failed to select entries")
+			return newActionResult(m.indexingCmd())
+		}
+		m.setExitWithSeparator(exitSeparatorNewline, paths...)
+		m.clearSearch()
+		return newActionResult(tea.Sequence(tea.ClearScreen, tea.Quit))
+	}
+
 	// If in normal mode with filtered view, return all fuzzy match results
-	if !m.modeSearch && m.search != "" {
+	if !m.modeSearch && m.search != "" && !m.flatSearchResults() {
 		if len(m.searchMatchNodes) == 0 {
 			return newActionResult(m.indexingCmd())
 		}
@@ -501,15 +650,15 @@ func (m *model) treeSelectAction() actionResult {
 					// Skip symlinks that can't be resolved
 					continue
 				}
-				path = sanitize.SanitizeOutputPath(sl.absPath)
+				path = sl.absPath
 			} else {
-				path = sanitize.SanitizeOutputPath(node.fullPath)
+				path = node.fullPath
 			}
 			paths = append(paths, path)
 		}
 		if len(paths) > 0 {
 			// Output one path per line
-			m.setExit(strings.Join(paths, "\n"))
+			m.setExitWithSeparator(exitSeparatorNewline, paths...)
 			m.clearSearch()
 			return newActionResult(tea.Sequence(tea.ClearScreen, tea.Quit))
 		}
@@ -525,7 +674,7 @@ func (m *model) treeSelectAction() actionResult {
 
 	// For files: return path and quit
 	if node.entry.hasMode(entryModeFile) {
-		m.setExit(sanitize.SanitizeOutputPath(node.fullPath))
+		m.setExit(node.fullPath)
 		// Clear screen if exiting from search
 		if m.search != "" {
 			return newActionResult(tea.Sequence(tea.ClearScreen, tea.Quit))
@@ -535,7 +684,7 @@ func (m *model) treeSelectAction() actionResult {
 
 	// For directories: return path and quit (same as files)
 	if node.entry.hasMode(entryModeDir) {
-		m.setExit(sanitize.SanitizeOutputPath(node.fullPath))
+		m.setExit(node.fullPath)
 		// Clear screen if exiting from search
 		if m.search != "" {
 			return newActionResult(tea.Sequence(tea.ClearScreen, tea.Quit))
@@ -552,7 +701,7 @@ func (m *model) treeSelectAction() actionResult {
 		}
 		if !sl.info.IsDir() {
 			// The symlink points to a file.
-			m.setExit(sanitize.SanitizeOutputPath(sl.absPath))
+			m.setExit(sl.absPath)
 			// Clear screen if exiting from search
 			if m.search != "" {
 				return newActionResult(tea.Sequence(tea.ClearScreen, tea.Quit))
@@ -560,7 +709,7 @@ func (m *model) treeSelectAction() actionResult {
 			return newActionResult(tea.Quit)
 		}
 		// The symlink points to a directory: return path and quit
-		m.setExit(sanitize.SanitizeOutputPath(sl.absPath))
+		m.setExit(sl.absPath)
 		// Clear screen if exiting from search
 		if m.search != "" {
 			return newActionResult(tea.Sequence(tea.ClearScreen, tea.Quit))
@@ -586,45 +735,16 @@ func actionModeGeneral(m *model, msg tea.KeyMsg, esc bool) actionResult {
 	// Return
 
 	case key.Matches(msg, keyReturnDirectory):
-		m.setExit(sanitize.SanitizeOutputPath(m.path))
+		m.setExit(m.path)
 		return newActionResult(tea.Quit)
 
 	case key.Matches(msg, keyReturnSelected):
-		selecteds := []*entry{}
-		paths := []string{}
-
-		if m.modeMarks {
-			for _, entryIdx := range m.marks {
-				if entryIdx < len(m.entries) {
-					selecteds = append(selecteds, m.entries[entryIdx])
-				}
-			}
-			sortEntries(selecteds)
-		} else {
-			selected, err := m.selected()
-			if err != nil {
-				m.setError(err, "failed to select entry")
-				return newActionResult(m.indexingCmd())
-			}
-			selecteds = append(selecteds, selected)
-		}
-
-		for _, selected := range selecteds {
-			var path string
-			if selected.hasMode(entryModeSymlink) {
-				sl, err := followSymlink(m.path, selected)
-				if err != nil {
-					m.setError(err, "failed to evaluate symlink")
-					return newActionResult(m.indexingCmd())
-				}
-				path = sanitize.SanitizeOutputPath(sl.absPath)
-			} else {
-				path = sanitize.SanitizeOutputPath(filepath.Join(m.path, selected.Name()))
-			}
-			paths = append(paths, path)
+		paths, err := m.selectedPaths()
+		if err != nil {
+			m.setError(err, "failed to select entries")
+			return newActionResult(m.indexingCmd())
 		}
-
-		m.setExit(strings.Join(paths, " "))
+		m.setExit(paths...)
 		return newActionResult(tea.Quit)
 
 	// Cursor
@@ -644,7 +764,6 @@ func actionModeGeneral(m *model, msg tea.KeyMsg, esc bool) actionResult {
 	// Selectors
 
 	case key.Matches(msg, keySelect):
-		m.clearMarks()
 		_, cmd := m.selectAction()
 		return newActionResult(cmd)
 
@@ -671,7 +790,6 @@ func actionModeGeneral(m *model, msg tea.KeyMsg, esc bool) actionResult {
 		}
 
 		m.clearSearch()
-		m.clearMarks()
 
 		// Return to ensure the cursor is not re-saved using the updated path.
 		return newActionResult(nil)
@@ -695,6 +813,10 @@ func actionModeGeneral(m *model, msg tea.KeyMsg, esc bool) actionResult {
 			return newActionResult(nil)
 		}
 
+	case key.Matches(msg, keyModeBasket):
+		m.openBasket()
+		return newActionResult(m.indexingCmd())
+
 	// Change modes
 
 	case key.Matches(msg, keyModeHelp):
@@ -703,7 +825,6 @@ func actionModeGeneral(m *model, msg tea.KeyMsg, esc bool) actionResult {
 
 	case key.Matches(msg, keyModeSearch):
 		m.modeSearch = true
-		m.clearMarks()
 		// If in tree mode with index, start search worker
 		if m.modeTree && len(m.searchIndexNodes) > 0 {
 			return newActionResult(m.startSearchWorker())
@@ -717,13 +838,131 @@ func actionModeGeneral(m *model, msg tea.KeyMsg, esc bool) actionResult {
 	case key.Matches(msg, keyToggleHidden):
 		m.modeHidden = !m.modeHidden
 		if m.modeTree {
-			m.rebuildVisibleNodes()
-			return newActionResult(m.indexingCmd())
+			return newActionResult(m.reindexTree())
+		}
+
+	case key.Matches(msg, keyToggleIgnored):
+		m.modeIgnored = !m.modeIgnored
+		if m.modeTree {
+			return newActionResult(m.reindexTree())
 		}
 
 	case key.Matches(msg, keyToggleList):
 		m.modeList = !m.modeList
 
+	case key.Matches(msg, keyTogglePreview):
+		m.modePreview = !m.modePreview
+		if !m.modePreview {
+			m.preview = nil
+		}
+
+	// File operations
+
+	case key.Matches(msg, keyRename):
+		m.promptRename()
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyNewFile):
+		m.promptCreate(false)
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyNewDir):
+		m.promptCreate(true)
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyCopy):
+		m.promptTransfer(false)
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyMove):
+		m.promptTransfer(true)
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyDelete):
+		m.promptDelete()
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyModeTrash):
+		m.openTrash()
+		return newActionResult(m.indexingCmd())
+
+	// Bookmarks
+
+	case key.Matches(msg, keyAddBookmark):
+		m.promptBookmark()
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyModeBookmarks):
+		m.openBookmarks()
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyJumpBookmark):
+		return newActionResult(tea.Batch(m.jumpToBookmarkKey(msg), m.indexingCmd()))
+
+	case key.Matches(msg, keyModeFrecency):
+		m.openFrecency()
+		return newActionResult(m.indexingCmd())
+
+	// History
+
+	case key.Matches(msg, keyHistoryBack):
+		return newActionResult(tea.Batch(m.moveHistory(-1), m.indexingCmd()))
+
+	case key.Matches(msg, keyHistoryForward):
+		return newActionResult(tea.Batch(m.moveHistory(1), m.indexingCmd()))
+
+	case key.Matches(msg, keyModeHistory):
+		m.openHistory()
+		return newActionResult(m.indexingCmd())
+
+	// Tabs
+
+	case key.Matches(msg, keyNewTab):
+		return newActionResult(tea.Batch(m.openTab(), m.indexingCmd()))
+
+	case key.Matches(msg, keyCloseTab):
+		return newActionResult(tea.Batch(m.closeTab(), m.indexingCmd()))
+
+	case key.Matches(msg, keyNextTab):
+		return newActionResult(tea.Batch(m.cycleTab(1), m.indexingCmd()))
+
+	case key.Matches(msg, keyPrevTab):
+		return newActionResult(tea.Batch(m.cycleTab(-1), m.indexingCmd()))
+
+	case key.Matches(msg, keyMoveTabLeft):
+		m.moveTab(-1)
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyMoveTabRight):
+		m.moveTab(1)
+		return newActionResult(m.indexingCmd())
+
+	// Panes
+
+	case key.Matches(msg, keyToggleDualPane):
+		return newActionResult(tea.Batch(m.toggleDualPane(), m.indexingCmd()))
+
+	case key.Matches(msg, keySwitchPane):
+		return newActionResult(tea.Batch(m.switchPane(), m.indexingCmd()))
+
+	case key.Matches(msg, keyComparePanes):
+		m.comparePanes()
+		return newActionResult(m.indexingCmd())
+
+	case key.Matches(msg, keyReturnPanes):
+		m.setExit(m.panePaths()...)
+		return newActionResult(tea.Quit)
+
+	// Sorting
+
+	case key.Matches(msg, keySort):
+		m.sort.mode = m.sort.mode.next()
+		return newActionResult(m.resort())
+
+	case key.Matches(msg, keySortReverse):
+		m.sort.reverse = !m.sort.reverse
+		return newActionResult(m.resort())
+
 	case key.Matches(msg, keyToggleTree):
 		m.modeTree = !m.modeTree
 		if m.modeTree {
@@ -736,6 +975,8 @@ func actionModeGeneral(m *model, msg tea.KeyMsg, esc bool) actionResult {
 			} else {
 				m.treeIdx = 0
 				m.scrollOffset = 0
+				// The grid and the tree keep their own marks.
+				m.modeMarks = len(m.treeMarks) != 0
 				return newActionResult(cmd)
 			}
 		} else {
@@ -751,6 +992,7 @@ func actionModeGeneral(m *model, msg tea.KeyMsg, esc bool) actionResult {
 				m.modeTree = true
 			} else {
 				m.resetCursor()
+				m.modeMarks = len(m.basket) != 0
 			}
 		}
 
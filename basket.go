@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// basketPicker lists the marks of the current view mode, which in the grid form a basket of
+// entries collected from any number of directories.
+type basketPicker struct {
+	listView
+	paths []string
+}
+
+func (m *model) openBasket() {
+	m.modeBasket = true
+	m.basketView = &basketPicker{listView: listView{title: " Basket"}}
+	m.loadBasketPicker()
+}
+
+func (m *model) closeBasket() {
+	m.modeBasket = false
+	m.basketView = nil
+}
+
+func (m *model) loadBasketPicker() {
+	paths := m.markedPaths()
+	lines := make([]string, len(paths))
+	for i, path := range paths {
+		lines[i] = sanitizePreviewLine(substituteHomeDir(path))
+	}
+	m.basketView.paths = paths
+	m.basketView.setLines(lines)
+}
+
+// removeFromBasket removes the path under the cursor of the basket view from the marks.
+func (m *model) removeFromBasket() {
+	if m.basketView.idx >= len(m.basketView.paths) {
+		return
+	}
+	marks := m.markSet()
+	delete(marks, m.basketView.paths[m.basketView.idx])
+	m.modeMarks = len(marks) != 0
+	m.loadBasketPicker()
+}
+
+func actionModeBasket(m *model, msg tea.KeyMsg, esc bool) actionResult {
+	switch {
+
+	case esc || key.Matches(msg, keyEsc) || key.Matches(msg, keyModeBasket):
+		m.closeBasket()
+
+	case key.Matches(msg, keyUp):
+		m.basketView.moveUp()
+
+	case key.Matches(msg, keyDown):
+		m.basketView.moveDown()
+
+	case key.Matches(msg, keySelect):
+		if m.basketView.idx < len(m.basketView.paths) {
+			path := m.basketView.paths[m.basketView.idx]
+			m.closeBasket()
+			return newActionResult(tea.Batch(m.jumpToEntry(path), m.indexingCmd()))
+		}
+
+	case key.Matches(msg, keyDelete):
+		m.removeFromBasket()
+
+	}
+
+	// Unconditional return to disable all other functionality.
+	return newActionResult(m.indexingCmd())
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/dkaslovsky/nav/internal/bookmarks"
+)
+
+// commandBookmarks is the subcommand that edits bookmarks from the command line.
+const commandBookmarks = "bookmarks"
+
+func loadBookmarks() (*bookmarks.Bookmarks, error) {
+	file, err := bookmarks.File()
+	if err != nil {
+		return nil, err
+	}
+	return bookmarks.Load(file)
+}
+
+// bookmarkPath returns the path of the named bookmark.
+func bookmarkPath(name string) (string, error) {
+	b, err := loadBookmarks()
+	if err != nil {
+		return "", err
+	}
+	bookmark, ok := b.Get(name)
+	if !ok {
+		return "", fmt.Errorf("no bookmark named %q", name)
+	}
+	return bookmark.Path, nil
+}
+
+// bookmarkPicker lists the bookmarks in the order they were added, which is the order of the
+// quick-jump keys.
+type bookmarkPicker struct {
+	listView
+	list []bookmarks.Bookmark
+}
+
+func (m *model) openBookmarks() {
+	m.modeBookmarks = true
+	m.bookmarks = &bookmarkPicker{listView: listView{title: " Bookmarks"}}
+	if err := m.loadBookmarkPicker(); err != nil {
+		m.setError(err, "failed to load bookmarks")
+	}
+}
+
+func (m *model) closeBookmarks() {
+	m.modeBookmarks = false
+	m.bookmarks = nil
+}
+
+func (m *model) loadBookmarkPicker() error {
+	b, err := loadBookmarks()
+	if err != nil {
+		return err
+	}
+	list := b.List()
+
+	width := 0
+	for _, bookmark := range list {
+		width = max(width, len(bookmark.Name))
+	}
+	jumpKeys := keyJumpBookmark.Keys()
+	lines := make([]string, len(list))
+	for i, bookmark := range list {
+		jumpKey := ""
+		if i < len(jumpKeys) {
+			jumpKey = jumpKeys[i]
+		}
+		lines[i] = fmt.Sprintf(
+			"%2s  %-*s    %s", jumpKey, width, bookmark.Name, sanitizePreviewLine(substituteHomeDir(bookmark.Path)),
+		)
+	}
+	m.bookmarks.list = list
+	m.bookmarks.setLines(lines)
+	return nil
+}
+
+// promptBookmark asks for the name of a bookmark to the directory under the cursor or, if the
+// entry under the cursor is not a directory, to the current directory.
+func (m *model) promptBookmark() {
+	dir := m.path
+	if path, err := m.cursorPath(); err == nil {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dir = path
+		}
+	}
+
+	m.setPrompt(&prompt{
+		label: fmt.Sprintf("bookmark %s as", substituteHomeDir(dir)),
+		input: filepath.Base(dir),
+		action: func(m *model, input string) (string, error) {
+			b, err := loadBookmarks()
+			if err != nil {
+				return "", err
+			}
+			if err := b.Add(input, dir); err != nil {
+				return "", err
+			}
+			return "", b.Save()
+		},
+		status: "failed to add bookmark",
+	})
+}
+
+// jumpToBookmarkKey changes to the bookmark at the position of the pressed key among the keys
+// bound to the quick jump.
+func (m *model) jumpToBookmarkKey(msg tea.KeyMsg) tea.Cmd {
+	idx := -1
+	for i, k := range keyJumpBookmark.Keys() {
+		if k == msg.String() {
+			idx = i
+			break
+		}
+	}
+	b, err := loadBookmarks()
+	if err != nil {
+		m.setError(err, "failed to load bookmarks")
+		return nil
+	}
+	list := b.List()
+	if idx < 0 || idx >= len(list) {
+		m.setError(fmt.Errorf("no bookmark %d", idx+1), "failed to jump to bookmark")
+		return nil
+	}
+	return m.jumpTo(list[idx].Path)
+}
+
+func actionModeBookmarks(m *model, msg tea.KeyMsg, esc bool) actionResult {
+	switch {
+
+	case esc || key.Matches(msg, keyEsc) || key.Matches(msg, keyModeBookmarks):
+		m.closeBookmarks()
+
+	case key.Matches(msg, keyUp):
+		m.bookmarks.moveUp()
+
+	case key.Matches(msg, keyDown):
+		m.bookmarks.moveDown()
+
+	case key.Matches(msg, keySelect):
+		if m.bookmarks.idx < len(m.bookmarks.list) {
+			path := m.bookmarks.list[m.bookmarks.idx].Path
+			m.closeBookmarks()
+			return newActionResult(m.jumpTo(path))
+		}
+
+	case key.Matches(msg, keyJumpBookmark):
+		m.closeBookmarks()
+		return newActionResult(m.jumpToBookmarkKey(msg))
+
+	case key.Matches(msg, keyDelete):
+		if m.bookmarks.idx < len(m.bookmarks.list) {
+			m.promptRemoveBookmark(m.bookmarks.list[m.bookmarks.idx].Name)
+		}
+
+	}
+
+	// Unconditional return to disable all other functionality.
+	return newActionResult(m.indexingCmd())
+}
+
+// promptRemoveBookmark asks for confirmation before removing a bookmark.
+func (m *model) promptRemoveBookmark(name string) {
+	m.setPrompt(&prompt{
+		label:   fmt.Sprintf("remove bookmark %s", name),
+		confirm: true,
+		action: func(m *model, _ string) (string, error) {
+			b, err := loadBookmarks()
+			if err != nil {
+				return "", err
+			}
+			if err := b.Remove(name); err != nil {
+				return "", err
+			}
+			return "", b.Save()
+		},
+		status: "failed to remove bookmark",
+	})
+}
+
+// runBookmarksCommand edits the bookmarks with the arguments following the bookmarks subcommand:
+// "add NAME [PATH]", "rm NAME", or "ls".
+func runBookmarksCommand(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("expected a bookmarks command: add NAME [PATH], rm NAME, or ls")
+	}
+
+	b, err := loadBookmarks()
+	if err != nil {
+		return err
+	}
+
+	switch command, args := args[0], args[1:]; command {
+	case "add":
+		if len(args) < 1 || len(args) > 2 {
+			return errors.New("usage: bookmarks add NAME [PATH]")
+		}
+		path := "."
+		if len(args) == 2 {
+			path = args[1]
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", path)
+		}
+		if err := b.Add(args[0], path); err != nil {
+			return err
+		}
+		return b.Save()
+
+	case "rm":
+		if len(args) != 1 {
+			return errors.New("usage: bookmarks rm NAME")
+		}
+		if err := b.Remove(args[0]); err != nil {
+			return err
+		}
+		return b.Save()
+
+	case "ls":
+		if len(args) != 0 {
+			return errors.New("usage: bookmarks ls")
+		}
+		list := b.List()
+		width := 0
+		for _, bookmark := range list {
+			width = max(width, len(bookmark.Name))
+		}
+		for _, bookmark := range list {
+			if _, err := fmt.Fprintf(w, "%-*s  %s\n", width, bookmark.Name, bookmark.Path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown bookmarks command %q, expected add, rm, or ls", args[0])
+}
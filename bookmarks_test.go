@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunBookmarksCommand(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		err := runBookmarksCommand(args, &out)
+		return out.String(), err
+	}
+
+	if _, err := run("add", "root", root); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("add", "missing", filepath.Join(root, "missing")); err == nil {
+		t.Fatal("expected error adding a missing directory")
+	}
+	if _, err := run("add", "data", filepath.Join(root, "data")); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := run("ls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("root  %s\ndata  %s\n", root, filepath.Join(root, "data"))
+	if out != want {
+		t.Fatalf("expected output %q, got %q", want, out)
+	}
+
+	path, err := bookmarkPath("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(root, "data") {
+		t.Fatalf("expected path %s, got %s", filepath.Join(root, "data"), path)
+	}
+
+	if _, err := run("rm", "data"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bookmarkPath("data"); err == nil {
+		t.Fatal("expected error for a removed bookmark")
+	}
+	if _, err := run("mv", "root"); err == nil {
+		t.Fatal("expected error for an unknown command")
+	}
+}
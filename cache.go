@@ -2,6 +2,8 @@ package main
 
 type cacheItem struct {
 	cursorPosition *position
+	cursorName     string // Name of the entry under the cursor, which is stable across re-sorts.
+	treeCursorPath string // Path of the tree node under the cursor in tree view mode.
 	entryToDisplay map[int]int
 	displayToEntry map[int]int
 	columns        int
@@ -34,6 +36,14 @@ func (ci *cacheItem) setPosition(pos *position) {
 	ci.cursorPosition = pos
 }
 
+func (ci *cacheItem) setCursorName(name string) {
+	ci.cursorName = name
+}
+
+func (ci *cacheItem) setTreeCursorPath(path string) {
+	ci.treeCursorPath = path
+}
+
 func (ci *cacheItem) setColumns(c int) {
 	ci.columns = c
 }
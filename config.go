@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dkaslovsky/nav/internal/quote"
+	"github.com/dkaslovsky/nav/internal/xdg"
+)
+
+const (
+	// envConfig overrides the default configuration file location.
+	envConfig      = "NAV_CONFIG"
+	configFileName = "config.toml"
+)
+
+// configPath returns the location of the configuration file and whether it was set explicitly.
+func configPath() (string, bool, error) {
+	if path := os.Getenv(envConfig); path != "" {
+		return path, true, nil
+	}
+	dir, err := xdg.ConfigHome()
+	if err != nil {
+		return "", false, err
+	}
+	return filepath.Join(dir, name, configFileName), false, nil
+}
+
+// loadConfig sets model options from the configuration file. A missing file is not an error
+// unless its location was set explicitly.
+func (m *model) loadConfig() error {
+	path, explicit, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	values, err := parseConfig(f)
+	if err != nil {
+		return fmt.Errorf("%s:%w", path, err)
+	}
+	for _, v := range values {
+		if err := m.applyConfig(v); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, v.line, err)
+		}
+	}
+	return nil
+}
+
+// applyConfig sets the model option corresponding to a configuration value.
+func (m *model) applyConfig(v *configValue) error {
+	switch v.section {
+	case "":
+		switch v.key {
+		case "hidden":
+			return v.setBool(&m.modeHidden)
+		case "show-ignored":
+			return v.setBool(&m.modeIgnored)
+		case "list":
+			return v.setBool(&m.modeList)
+		case "tree":
+			return v.setBool(&m.modeTree)
+		case "follow":
+			return v.setBool(&m.modeFollowSymlink)
+		case "preview":
+			return v.setBool(&m.modePreview)
+		case "color":
+			return v.setBool(&m.modeColor)
+		case "trailing":
+			return v.setBool(&m.modeTrailing)
+		case "output":
+			s, err := v.asString()
+			if err != nil {
+				return err
+			}
+			m.output, err = parseOutputFormat(s)
+			return err
+		case "flat":
+			return v.setBool(&m.modeSearchFlat)
+		case "search-paths":
+			return v.setBool(&m.modeSearchPaths)
+		case "search-strategy":
+			s, err := v.asString()
+			if err != nil {
+				return err
+			}
+			m.searchStrategy, err = parseSearchStrategy(s)
+			return err
+		case "quote":
+			s, err := v.asString()
+			if err != nil {
+				return err
+			}
+			m.quote, err = quote.ParseStyle(s)
+			return err
+		case "git":
+			return v.setBool(&m.modeGit)
+		case "watch":
+			return v.setBool(&m.modeWatch)
+		case "index-cache":
+			return v.setBool(&m.modeIndexCache)
+		case "status-bar":
+			show, err := v.asBool()
+			if err != nil {
+				return err
+			}
+			m.hideStatusBar = !show
+			return nil
+		case "theme":
+			s, err := v.asString()
+			if err != nil {
+				return err
+			}
+			m.theme = s
+			return nil
+		case "ls-colors":
+			return v.setBool(&m.modeLSColors)
+		case "sort":
+			s, err := v.asString()
+			if err != nil {
+				return err
+			}
+			m.sort.mode, err = parseSortMode(s)
+			return err
+		case "sort-reverse":
+			return v.setBool(&m.sort.reverse)
+		case "sort-ignore-case":
+			return v.setBool(&m.sort.ignoreCase)
+		case "sort-dirs-first":
+			return v.setBool(&m.sort.dirsFirst)
+		case "remap-esc":
+			s, err := v.asString()
+			if err != nil {
+				return err
+			}
+			return m.setEscRemapKey(s)
+		}
+	case "keys":
+		keys, err := v.asStrings()
+		if err != nil {
+			return err
+		}
+		return bindKey(v.key, keys)
+	}
+	return fmt.Errorf("unknown key %q", v.name())
+}
+
+// configValue is a single key/value assignment read from the configuration file.
+type configValue struct {
+	section string
+	key     string
+	value   any // One of bool, int64, string, or []string.
+	line    int
+}
+
+func (v *configValue) name() string {
+	if v.section == "" {
+		return v.key
+	}
+	return v.section + "." + v.key
+}
+
+func (v *configValue) asBool() (bool, error) {
+	b, ok := v.value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%q must be a boolean", v.name())
+	}
+	return b, nil
+}
+
+func (v *configValue) setBool(b *bool) error {
+	val, err := v.asBool()
+	if err != nil {
+		return err
+	}
+	*b = val
+	return nil
+}
+
+func (v *configValue) asString() (string, error) {
+	s, ok := v.value.(string)
+	if !ok {
+		return "", fmt.Errorf("%q must be a string", v.name())
+	}
+	return s, nil
+}
+
+// asStrings returns the value as a list of strings, accepting a single string as a list of one.
+func (v *configValue) asStrings() ([]string, error) {
+	switch val := v.value.(type) {
+	case string:
+		return []string{val}, nil
+	case []string:
+		return val, nil
+	}
+	return nil, fmt.Errorf("%q must be a string or an array of strings", v.name())
+}
+
+// parseConfig parses the subset of TOML used by the configuration file: comments, [section]
+// headers, and key = value pairs where the value is a boolean, an integer, a basic or literal
+// string, or a single-line array of strings. Errors are prefixed with the offending line number.
+func parseConfig(r io.Reader) ([]*configValue, error) {
+	var (
+		values  = []*configValue{}
+		seen    = make(map[string]bool)
+		section = ""
+		lineNum = 0
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripConfigComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%d: unterminated section header", lineNum)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if !validConfigKey(section) {
+				return nil, fmt.Errorf("%d: invalid section name %q", lineNum, section)
+			}
+			continue
+		}
+
+		k, val, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("%d: expected key = value", lineNum)
+		}
+		k = strings.TrimSpace(k)
+		if unquoted, err := strconv.Unquote(k); err == nil && strings.HasPrefix(k, `"`) {
+			k = unquoted
+		} else if !validConfigKey(k) {
+			return nil, fmt.Errorf("%d: invalid key %q", lineNum, k)
+		}
+
+		value, err := parseConfigValue(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("%d: invalid value for %q: %w", lineNum, k, err)
+		}
+
+		v := &configValue{section: section, key: k, value: value, line: lineNum}
+		if seen[v.name()] {
+			return nil, fmt.Errorf("%d: duplicate key %q", lineNum, v.name())
+		}
+		seen[v.name()] = true
+		values = append(values, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%d: %w", lineNum, err)
+	}
+
+	return values, nil
+}
+
+func parseConfigValue(s string) (any, error) {
+	switch {
+	case s == "":
+		return nil, errors.New("missing value")
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case strings.HasPrefix(s, `"`), strings.HasPrefix(s, "'"):
+		return parseConfigString(s)
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, errors.New("unterminated array")
+		}
+		items := []string{}
+		for _, item := range splitConfigArray(s[1 : len(s)-1]) {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			str, err := parseConfigString(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, str)
+		}
+		return items, nil
+	}
+
+	i, err := strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized value %s", s)
+	}
+	return i, nil
+}
+
+func parseConfigString(s string) (string, error) {
+	if len(s) >= 2 && strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") {
+		lit := s[1 : len(s)-1]
+		if strings.Contains(lit, "'") {
+			return "", errors.New("unexpected ' in literal string")
+		}
+		return lit, nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		str, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("malformed string %s", s)
+		}
+		return str, nil
+	}
+	return "", fmt.Errorf("expected a string but found %s", s)
+}
+
+// stripConfigComment removes a trailing comment from a line, ignoring '#' within strings.
+func stripConfigComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++ // Skip the escaped character.
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitConfigArray splits the contents of an array on commas that are not within strings.
+func splitConfigArray(s string) []string {
+	var (
+		items []string
+		quote byte
+		start = 0
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && c == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+func validConfigKey(k string) bool {
+	if k == "" {
+		return false
+	}
+	for _, r := range k {
+		if !(r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
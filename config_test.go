@@ -0,0 +1,253 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := map[string]struct {
+		config  string
+		want    []*configValue
+		wantErr string
+	}{
+		"empty": {
+			config: "",
+			want:   []*configValue{},
+		},
+		"values": {
+			config: strings.Join([]string{
+				"# comment",
+				"hidden = true",
+				"list = false # trailing comment",
+				`remap-esc = ";;"`,
+				"",
+				"[section]",
+				"count = 1_000",
+				`keys = ["a", 'b#', "c,d"]`,
+			}, "\n"),
+			want: []*configValue{
+				{section: "", key: "hidden", value: true, line: 2},
+				{section: "", key: "list", value: false, line: 3},
+				{section: "", key: "remap-esc", value: ";;", line: 4},
+				{section: "section", key: "count", value: int64(1000), line: 7},
+				{section: "section", key: "keys", value: []string{"a", "b#", "c,d"}, line: 8},
+			},
+		},
+		"missing_equals": {
+			config:  "hidden = true\nlist",
+			wantErr: "2: expected key = value",
+		},
+		"invalid_value": {
+			config:  "\n\nhidden = yes",
+			wantErr: `3: invalid value for "hidden": unrecognized value yes`,
+		},
+		"duplicate_key": {
+			config:  "hidden = true\nhidden = false",
+			wantErr: `2: duplicate key "hidden"`,
+		},
+		"unterminated_section": {
+			config:  "[keys",
+			wantErr: "1: unterminated section header",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got, err := parseConfig(strings.NewReader(test.config))
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					tt.Fatalf("expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := map[string]struct {
+		config  string
+		check   func(*model) bool
+		wantErr string
+	}{
+		"options": {
+			config: "hidden = true\ncolor = false\nstatus-bar = false\ntree = true",
+			check: func(m *model) bool {
+				return m.modeHidden && !m.modeColor && m.hideStatusBar && m.modeTree
+			},
+		},
+		"unknown_key": {
+			config:  "hidden = true\nhiden = true",
+			wantErr: `:2: unknown key "hiden"`,
+		},
+		"wrong_type": {
+			config:  `list = "yes"`,
+			wantErr: `:1: "list" must be a boolean`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			path := filepath.Join(tt.TempDir(), configFileName)
+			if err := os.WriteFile(path, []byte(test.config), 0o600); err != nil {
+				tt.Fatal(err)
+			}
+			tt.Setenv(envConfig, path)
+
+			m := newModel()
+			err := m.loadConfig()
+			if test.wantErr != "" {
+				if err == nil || err.Error() != path+test.wantErr {
+					tt.Fatalf("expected error %q, got %v", path+test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %v", err)
+			}
+			if !test.check(m) {
+				tt.Fatal("config options not applied to model")
+			}
+		})
+	}
+}
+
+func TestFlagsOverrideConfig(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		args   []string
+		check  func(*model) bool
+	}{
+		"turn_off": {
+			config: "hidden = true\ntree = true\nlist = true\nflat = true\nsort-reverse = true\nindex-cache = true",
+			args:   []string{"--no-hidden", "--no-tree", "--no-list", "--no-flat", "--no-reverse", "--no-index-cache"},
+			check: func(m *model) bool {
+				return !m.modeHidden && !m.modeTree && !m.modeList && !m.modeSearchFlat && !m.sort.reverse &&
+					!m.modeIndexCache
+			},
+		},
+		"turn_on": {
+			config: "git = false\ncolor = false\nstatus-bar = false\nsort-dirs-first = false\nindex-cache = false",
+			args:   []string{"--git", "--color", "--status-bar", "--dirs-first", "--index-cache"},
+			check: func(m *model) bool {
+				return m.modeGit && m.modeColor && !m.hideStatusBar && m.sort.dirsFirst && m.modeIndexCache
+			},
+		},
+		"config_without_flags": {
+			config: "hidden = true\ngit = false",
+			check: func(m *model) bool {
+				return m.modeHidden && !m.modeGit
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			path := filepath.Join(tt.TempDir(), configFileName)
+			if err := os.WriteFile(path, []byte(test.config), 0o600); err != nil {
+				tt.Fatal(err)
+			}
+			tt.Setenv(envConfig, path)
+
+			m := newModel()
+			if err := m.loadConfig(); err != nil {
+				tt.Fatal(err)
+			}
+			if err := parseArgs(append(test.args, tt.TempDir()), m); err != nil {
+				tt.Fatal(err)
+			}
+			if !test.check(m) {
+				tt.Fatal("flags did not override the config options")
+			}
+		})
+	}
+}
+
+func TestConfigKeyBindings(t *testing.T) {
+	tests := map[string]struct {
+		config       string
+		wantErr      string
+		wantConflict string
+	}{
+		"defaults": {
+			config: "",
+		},
+		"rebind": {
+			config: "[keys]\nmark = \"ctrl+b\"\nreturn-selected = [\"ctrl+r\", \"ctrl+x\"]",
+		},
+		"unknown_action": {
+			config:  "[keys]\nmarks = \"ctrl+b\"",
+			wantErr: `:2: unknown key action "marks"`,
+		},
+		"conflict": {
+			config:       "[keys]\nmark = \"a\"",
+			wantConflict: `key "a" is bound to both "mark" and "toggle-hidden"`,
+		},
+		"conflict_with_search_input": {
+			config:       "[keys]\nsearch-strategy = \" \"",
+			wantConflict: `key " " is bound to both "space" and "search-strategy"`,
+		},
+		"no_conflict_across_scopes": {
+			config: "[keys]\ncomplete = \"a\"",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			// Restore the global bindings after each test.
+			saved := make([]key.Binding, len(keyActions))
+			for i, a := range keyActions {
+				saved[i] = *a.binding
+			}
+			defer func() {
+				for i, a := range keyActions {
+					*a.binding = saved[i]
+				}
+			}()
+
+			path := filepath.Join(tt.TempDir(), configFileName)
+			if err := os.WriteFile(path, []byte(test.config), 0o600); err != nil {
+				tt.Fatal(err)
+			}
+			tt.Setenv(envConfig, path)
+
+			err := newModel().loadConfig()
+			if test.wantErr != "" {
+				if err == nil || err.Error() != path+test.wantErr {
+					tt.Fatalf("expected error %q, got %v", path+test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %v", err)
+			}
+
+			err = checkKeyConflicts()
+			if test.wantConflict != "" {
+				if err == nil || err.Error() != test.wantConflict {
+					tt.Fatalf("expected conflict %q, got %v", test.wantConflict, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected conflict: %v", err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -36,11 +37,21 @@ func (m *model) setCursor(pos *position) {
 
 func (m *model) saveCursor() {
 	pos := &position{c: m.c, r: m.r}
-	if cache, ok := m.pathCache[m.path]; ok {
-		cache.setPosition(pos)
-		return
+	cache, ok := m.pathCache[m.path]
+	if !ok {
+		cache = newCacheItemWithPosition(pos)
+		m.pathCache[m.path] = cache
+	} else if !m.modeTree {
+		if selected, err := m.selected(); err == nil {
+			cache.setCursorName(selected.Name())
+		}
+	}
+	if m.modeTree {
+		if node := m.selectedTreeNode(); node != nil && node.entry != nil {
+			cache.setTreeCursorPath(node.fullPath)
+		}
 	}
-	m.pathCache[m.path] = newCacheItemWithPosition(pos)
+	cache.setPosition(pos)
 }
 
 func (m *model) moveUp() {
@@ -94,6 +105,23 @@ func (m *model) moveRight() {
 
 // Tree-mode cursor movements
 
+// restoreTreeCursor places the tree cursor on the node saved for the current path or, when that
+// node is not visible, on its closest visible ancestor, falling back to the first node.
+func (m *model) restoreTreeCursor() {
+	m.treeIdx = 0
+	m.scrollOffset = 0
+	if cache, ok := m.pathCache[m.path]; ok && cache.treeCursorPath != "" {
+		for i, node := range m.visibleNodes {
+			// Ancestors precede their descendants, so the last match is the closest.
+			if node.fullPath == cache.treeCursorPath ||
+				strings.HasPrefix(cache.treeCursorPath, node.fullPath+string(filepath.Separator)) {
+				m.treeIdx = i
+			}
+		}
+	}
+	m.adjustScrollOffset()
+}
+
 func (m *model) treeMoveUp() {
 	m.treeIdx--
 	if m.treeIdx < 0 {
@@ -130,6 +158,7 @@ func (m *model) treeCollapse() {
 		m.search = ""
 		m.treeSearchStartNode = nil
 		m.searchMatchNodes = nil
+		m.searchHighlights = nil
 
 		// Rebuild visible nodes (unfiltered tree)
 		m.rebuildVisibleNodes()
@@ -174,6 +203,7 @@ func (m *model) treeCollapse() {
 				m.search = ""
 				m.treeSearchStartNode = nil
 				m.searchMatchNodes = nil
+				m.searchHighlights = nil
 				m.rebuildVisibleNodes()
 				for i, n := range m.visibleNodes {
 					if n.entry != nil && n.entry.Name() == childDirName {
@@ -201,6 +231,7 @@ func (m *model) treeCollapse() {
 
 		// Move cursor to parent and rebuild
 		m.rebuildVisibleNodes()
+		m.syncWatches()
 		for i, n := range m.visibleNodes {
 			if n == node.parent {
 				m.treeIdx = i
@@ -254,13 +285,18 @@ func (m *model) treeExpand() tea.Cmd {
 	}
 
 	if !node.expanded {
-		if err := node.loadChildren(); err != nil {
+		if err := node.loadChildren(m.sort); err != nil {
 			m.setError(err, "failed to read directory")
 			return nil
 		}
+		// The index loader walks the children concurrently, so they are refreshed once displayed.
+		if !m.searchIndexLoading {
+			node.refreshInfos(m.sort)
+		}
 
 		node.expanded = true
 		m.rebuildVisibleNodes()
+		m.syncWatches()
 
 		// Position cursor on last selected child if exists, otherwise first child
 		found := false
@@ -305,17 +341,23 @@ func (m *model) treeToggleExpand() tea.Cmd {
 		// Collapse: just set expanded to false and rebuild
 		node.expanded = false
 		m.rebuildVisibleNodes()
+		m.syncWatches()
 		m.adjustScrollOffset()
 		return nil
 	} else {
 		// Expand: load children and expand, but keep cursor on the directory (don't move into it)
-		if err := node.loadChildren(); err != nil {
+		if err := node.loadChildren(m.sort); err != nil {
 			m.setError(err, "failed to read directory")
 			return nil
 		}
+		// The index loader walks the children concurrently, so they are refreshed once displayed.
+		if !m.searchIndexLoading {
+			node.refreshInfos(m.sort)
+		}
 
 		node.expanded = true
 		m.rebuildVisibleNodes()
+		m.syncWatches()
 		// Keep cursor on the same directory (don't move to children)
 		// Find the node again after rebuild to ensure cursor stays on it
 		for i, n := range m.visibleNodes {
@@ -31,7 +31,7 @@ func newDisplayName(e *entry, opts ...displayNameOption) *displayName {
 		name:      e.Name(),
 		nameExtra: "",
 		trailing:  "",
-		color:     colorGray,
+		color:     "",
 		listInfo:  "",
 	}
 
@@ -39,22 +39,28 @@ func newDisplayName(e *entry, opts ...displayNameOption) *displayName {
 		opt(c, e.mode, e.info)
 	}
 
+	name, highlightLen := c.name, 0
+	if len(c.highlight) > 0 {
+		name, highlightLen = highlightName(c.name, c.highlight, c.highlightStyle, c.color)
+	}
+	if c.color != "" {
+		name = fmt.Sprintf("%s%s%s", c.color, name, colorReset)
+	}
+
 	return &displayName{
-		name: fmt.Sprintf("%s%s%s%s%s%s", c.listInfo, c.color, c.name, colorReset, c.trailing, c.nameExtra),
-		len:  len(c.name) + len(c.trailing) + len(c.nameExtra),
+		name: fmt.Sprintf("%s%s%s%s%s", c.listInfo, name, c.trailing, c.nameExtra, c.gitMarker),
+		len:  len(c.name) + highlightLen + len(c.trailing) + len(c.nameExtra) + c.gitMarkerLen,
 	}
 }
 
 type color string
 
-const (
-	colorReset   color = "\033[0m"
-	colorCyan    color = "\033[36m"
-	colorGreen   color = "\033[32m"
-	colorGray    color = "\033[37m"
-	colorMagenta color = "\033[35m"
-	colorYellow  color = "\033[33m"
-)
+const colorReset color = "\033[0m"
+
+// newColor returns the escape sequence for SGR parameters such as "01;34".
+func newColor(sgr string) color {
+	return color("\033[" + sgr + "m")
+}
 
 // displayNameConfig contains configuration values for constructing an entry's display name.
 type displayNameConfig struct {
@@ -63,23 +69,104 @@ type displayNameConfig struct {
 	nameExtra string
 	trailing  string
 	listInfo  string
+
+	gitMarker    string // Styled marker of the git state.
+	gitMarkerLen int    // Display width of gitMarker.
+
+	highlight      []int          // Byte offsets of the characters of the name matched by a search.
+	highlightStyle highlightStyle // How the matched characters are rendered.
 }
 
 // displayNameOption is a functional option for setting displayNameConfig values.
 type displayNameOption func(*displayNameConfig, entryMode, fs.FileInfo)
 
-func displayNameWithColor() displayNameOption {
+// displayNameWithColor colors the name of an entry in the directory dir.
+func displayNameWithColor(colors *entryColors, dir string) displayNameOption {
 	return func(c *displayNameConfig, mode entryMode, info fs.FileInfo) {
-		switch {
-		case mode.has(entryModeSymlink):
-			c.color = colorMagenta
-		case mode.has(entryModeHidden):
-			c.color = colorYellow
-		case mode.has(entryModeDir):
-			c.color = colorCyan
-		case mode.has(entryModeExec):
-			c.color = colorGreen
+		var (
+			sgr string
+			ok  bool
+		)
+		if mode.has(entryModeSymlink) && colors.linkTarget {
+			sgr, ok = colors.lookupLink(filepath.Join(dir, c.name))
+		} else {
+			sgr, ok = colors.lookup(c.name, mode, info)
 		}
+		if ok && sgr != "" {
+			c.color = newColor(sgr)
+		}
+	}
+}
+
+// highlightStyle selects how the characters of a name matched by a search are rendered.
+type highlightStyle int
+
+const (
+	highlightColor     highlightStyle = iota // The themed search match style.
+	highlightUnderline                       // Bold and underlined, without color.
+	highlightBrackets                        // Enclosed in brackets, for terminals without styling.
+)
+
+// displayNameWithHighlight highlights the characters of the name at the given byte offsets.
+func displayNameWithHighlight(offsets []int, style highlightStyle) displayNameOption {
+	return func(c *displayNameConfig, mode entryMode, info fs.FileInfo) {
+		c.highlight = offsets
+		c.highlightStyle = style
+	}
+}
+
+// highlightName renders the runes of name starting at the given byte offsets in the given style,
+// restoring the entry color after each highlighted run. It also returns the display width added to
+// the name by the highlighting.
+func highlightName(name string, offsets []int, style highlightStyle, entryColor color) (string, int) {
+	highlighted := make(map[int]bool, len(offsets))
+	for _, offset := range offsets {
+		highlighted[offset] = true
+	}
+
+	var b, run strings.Builder
+	added := 0
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		switch style {
+		case highlightBrackets:
+			b.WriteString("[" + run.String() + "]")
+			added += 2
+		case highlightUnderline:
+			b.WriteString(searchRendererMatchPlain.Render(run.String()))
+		default:
+			b.WriteString(searchRendererMatch.Render(run.String()))
+		}
+		b.WriteString(string(entryColor))
+		run.Reset()
+	}
+	for i, r := range name {
+		if highlighted[i] {
+			run.WriteRune(r)
+			continue
+		}
+		flush()
+		b.WriteRune(r)
+	}
+	flush()
+	return b.String(), added
+}
+
+// displayNameWithPath displays the given path in place of the name. Options that look up the entry
+// by its name must precede it.
+func displayNameWithPath(path string) displayNameOption {
+	return func(c *displayNameConfig, mode entryMode, info fs.FileInfo) {
+		c.name = path
+	}
+}
+
+// displayNameWithDim renders the name faint, on top of any color, to set apart entries such as
+// those ignored by git.
+func displayNameWithDim() displayNameOption {
+	return func(c *displayNameConfig, mode entryMode, info fs.FileInfo) {
+		c.color += newColor("2")
 	}
 }
 
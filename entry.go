@@ -1,6 +1,7 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"io/fs"
 	"os"
@@ -108,45 +109,177 @@ func followSymlink(path string, e *entry) (*symlink, error) {
 	}, nil
 }
 
-// sortEntries performs an in-place sort of a slice of entries by type (mode) and alphabetically
-// within each type (mode). The ordering of types (modes) is:
-// - directories
-// - files
-// - hidden files
-func sortEntries(entries []*entry) {
-	sort.Slice(entries, func(i, j int) bool {
-		iEntry := entries[i]
-		jEntry := entries[j]
-
-		if iEntry.hasMode(entryModeHidden) {
-			if jEntry.hasMode(entryModeHidden) {
-				if iEntry.hasMode(entryModeDir) {
-					if jEntry.hasMode(entryModeDir) {
-						return iEntry.Name() < jEntry.Name()
-					}
-					return true
-				}
-				if jEntry.hasMode(entryModeDir) {
-					return false
-				}
-				return iEntry.Name() < jEntry.Name()
-			}
-			return false
+// sortMode selects the key used to order entries.
+type sortMode int
+
+const (
+	sortModeName sortMode = iota
+	sortModeSize
+	sortModeTime
+	sortModeExtension
+	sortModeNatural
+)
+
+var sortModeNames = []string{"name", "size", "time", "extension", "natural"}
+
+func (s sortMode) String() string {
+	return sortModeNames[s]
+}
+
+// next returns the sort mode that follows s when cycling through all modes.
+func (s sortMode) next() sortMode {
+	return (s + 1) % sortMode(len(sortModeNames))
+}
+
+func parseSortMode(s string) (sortMode, error) {
+	for i, n := range sortModeNames {
+		if n == s {
+			return sortMode(i), nil
+		}
+	}
+	return sortModeName, fmt.Errorf("invalid sort mode %q, must be one of: %s", s, strings.Join(sortModeNames, ", "))
+}
+
+// sortOrder configures the ordering of entries.
+type sortOrder struct {
+	mode       sortMode
+	reverse    bool
+	ignoreCase bool
+	dirsFirst  bool
+}
+
+func defaultSortOrder() sortOrder {
+	return sortOrder{
+		mode:       sortModeName,
+		reverse:    false,
+		ignoreCase: false,
+		dirsFirst:  true,
+	}
+}
+
+// usesInfo returns true if the order compares the sizes or times of entries.
+func (o sortOrder) usesInfo() bool {
+	return o.mode == sortModeSize || o.mode == sortModeTime
+}
+
+// String describes the order for display, returning an empty string for the default order.
+func (o sortOrder) String() string {
+	if o == defaultSortOrder() {
+		return ""
+	}
+	desc := []string{o.mode.String()}
+	if o.reverse {
+		desc = append(desc, "reversed")
+	}
+	if o.ignoreCase {
+		desc = append(desc, "ignore case")
+	}
+	if !o.dirsFirst {
+		desc = append(desc, "mixed")
+	}
+	return strings.Join(desc, ", ")
+}
+
+// less reports whether entry a sorts before entry b. Hidden entries always follow all other
+// entries and, unless disabled, directories precede other entries within each group. Within a
+// group, entries are ordered by the sort mode with ties broken by name:
+// - name: alphabetically
+// - size: largest first
+// - time: most recently modified first
+// - extension: alphabetically by extension
+// - natural: alphabetically, comparing runs of digits numerically
+// Reversing the order reverses the ordering within each group.
+func (o sortOrder) less(a, b *entry) bool {
+	if aHidden, bHidden := a.hasMode(entryModeHidden), b.hasMode(entryModeHidden); aHidden != bHidden {
+		return bHidden
+	}
+	if o.dirsFirst {
+		if aDir, bDir := a.hasMode(entryModeDir), b.hasMode(entryModeDir); aDir != bDir {
+			return aDir
+		}
+	}
+
+	c := o.compare(a, b)
+	if o.reverse {
+		return c > 0
+	}
+	return c < 0
+}
+
+func (o sortOrder) compare(a, b *entry) int {
+	var c int
+	switch o.mode {
+	case sortModeSize:
+		c = cmp.Compare(b.info.Size(), a.info.Size())
+	case sortModeTime:
+		c = b.info.ModTime().Compare(a.info.ModTime())
+	case sortModeExtension:
+		c = o.compareNames(filepath.Ext(a.Name()), filepath.Ext(b.Name()))
+	case sortModeNatural:
+		if o.ignoreCase {
+			c = naturalCompare(strings.ToLower(a.Name()), strings.ToLower(b.Name()))
+		} else {
+			c = naturalCompare(a.Name(), b.Name())
 		}
-		if jEntry.hasMode(entryModeHidden) {
-			return true
+	}
+	if c != 0 {
+		return c
+	}
+	return o.compareNames(a.Name(), b.Name())
+}
+
+func (o sortOrder) compareNames(a, b string) int {
+	if o.ignoreCase {
+		if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+			return c
 		}
+	}
+	return strings.Compare(a, b)
+}
+
+// naturalCompare compares strings alphabetically except that runs of digits are compared by their
+// numeric value, so that "file2" sorts before "file10".
+func naturalCompare(a, b string) int {
+	isDigit := func(c byte) bool { return '0' <= c && c <= '9' }
 
-		if iEntry.hasMode(entryModeDir) {
-			if jEntry.hasMode(entryModeDir) {
-				return iEntry.Name() < jEntry.Name()
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if !isDigit(a[i]) || !isDigit(b[j]) {
+			if a[i] != b[j] {
+				return cmp.Compare(a[i], b[j])
 			}
-			return true
+			i++
+			j++
+			continue
+		}
+
+		// Compare digit runs by value: ignoring leading zeros, a longer run is larger and runs of
+		// equal length compare lexically.
+		iStart, jStart := i, j
+		for i < len(a) && isDigit(a[i]) {
+			i++
+		}
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+		aNum := strings.TrimLeft(a[iStart:i], "0")
+		bNum := strings.TrimLeft(b[jStart:j], "0")
+		if c := cmp.Compare(len(aNum), len(bNum)); c != 0 {
+			return c
 		}
-		if jEntry.hasMode(entryModeDir) {
-			return false
+		if c := strings.Compare(aNum, bNum); c != 0 {
+			return c
 		}
+	}
+	if c := cmp.Compare(len(a)-i, len(b)-j); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
 
-		return iEntry.Name() < jEntry.Name()
+// sortEntries performs an in-place stable sort of a slice of entries using the provided order.
+func sortEntries(entries []*entry, order sortOrder) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return order.less(entries[i], entries[j])
 	})
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"io/fs"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -69,7 +70,7 @@ func TestSortEntriesByType(t *testing.T) {
 				entries = append(entries, ent)
 			}
 
-			sortEntries(entries)
+			sortEntries(entries, defaultSortOrder())
 			if !testEntrySliceEqual(entries, test.want) {
 				tt.Fatal("incorrect sort order for entries")
 			}
@@ -77,26 +78,122 @@ func TestSortEntriesByType(t *testing.T) {
 	}
 }
 
+func TestSortEntriesByOrder(t *testing.T) {
+	now := time.Now()
+	entries := []*entry{
+		newEntryMust(newEntry(&mockDirEntry{name: "b10.txt", size: 10, modTime: now.Add(-time.Hour)})),
+		newEntryMust(newEntry(&mockDirEntry{name: "B2.go", size: 30, modTime: now.Add(-time.Minute)})),
+		newEntryMust(newEntry(&mockDirEntry{name: "a1.md", size: 20, modTime: now})),
+		newEntryMust(newEntry(&mockDirEntry{name: "dir", mode: fs.ModeDir})),
+		newEntryMust(newEntry(&mockDirEntry{name: ".hidden", size: 40})),
+	}
+
+	tests := map[string]struct {
+		order sortOrder
+		want  []string
+	}{
+		"name": {
+			order: defaultSortOrder(),
+			want:  []string{"dir", "B2.go", "a1.md", "b10.txt", ".hidden"},
+		},
+		"name_ignore_case": {
+			order: sortOrder{mode: sortModeName, ignoreCase: true, dirsFirst: true},
+			want:  []string{"dir", "a1.md", "b10.txt", "B2.go", ".hidden"},
+		},
+		"name_reverse": {
+			order: sortOrder{mode: sortModeName, reverse: true, dirsFirst: true},
+			want:  []string{"dir", "b10.txt", "a1.md", "B2.go", ".hidden"},
+		},
+		"size": {
+			order: sortOrder{mode: sortModeSize, dirsFirst: true},
+			want:  []string{"dir", "B2.go", "a1.md", "b10.txt", ".hidden"},
+		},
+		"size_mixed": {
+			order: sortOrder{mode: sortModeSize},
+			want:  []string{"B2.go", "a1.md", "b10.txt", "dir", ".hidden"},
+		},
+		"time": {
+			order: sortOrder{mode: sortModeTime, dirsFirst: true},
+			want:  []string{"dir", "a1.md", "B2.go", "b10.txt", ".hidden"},
+		},
+		"extension": {
+			order: sortOrder{mode: sortModeExtension, dirsFirst: true},
+			want:  []string{"dir", "B2.go", "a1.md", "b10.txt", ".hidden"},
+		},
+		"natural_ignore_case": {
+			order: sortOrder{mode: sortModeNatural, ignoreCase: true, dirsFirst: true},
+			want:  []string{"dir", "a1.md", "B2.go", "b10.txt", ".hidden"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			sorted := make([]*entry, len(entries))
+			copy(sorted, entries)
+
+			sortEntries(sorted, test.order)
+			got := []string{}
+			for _, ent := range sorted {
+				got = append(got, ent.Name())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestNaturalCompare(t *testing.T) {
+	tests := map[string]struct {
+		a, b string
+		want int
+	}{
+		"equal":          {a: "file1", b: "file1", want: 0},
+		"numeric":        {a: "file2", b: "file10", want: -1},
+		"leading_zeros":  {a: "file002", b: "file10", want: -1},
+		"zeros_tiebreak": {a: "file02", b: "file2", want: -1},
+		"prefix":         {a: "file", b: "file1", want: -1},
+		"versions":       {a: "v1.10.0", b: "v1.9.3", want: 1},
+		"letters":        {a: "b1", b: "a2", want: 1},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			if got := naturalCompare(test.a, test.b); got != test.want {
+				tt.Fatalf("expected %d, got %d", test.want, got)
+			}
+		})
+	}
+}
+
 // mockDirEntry provides a mock implementation of the fs.DirEntry interface for testing.
 type mockDirEntry struct {
-	name string
-	mode fs.FileMode
+	name    string
+	mode    fs.FileMode
+	size    int64
+	modTime time.Time
 }
 
-func (de *mockDirEntry) Name() string               { return de.name }
-func (de *mockDirEntry) IsDir() bool                { return de.mode&fs.ModeDir == fs.ModeDir }
-func (de *mockDirEntry) Info() (fs.FileInfo, error) { return &mockFileInfo{mode: de.mode}, nil }
-func (de *mockDirEntry) Type() fs.FileMode          { return fs.FileMode(0) } // Unused.
+func (de *mockDirEntry) Name() string { return de.name }
+func (de *mockDirEntry) IsDir() bool  { return de.mode&fs.ModeDir == fs.ModeDir }
+func (de *mockDirEntry) Info() (fs.FileInfo, error) {
+	return &mockFileInfo{mode: de.mode, size: de.size, modTime: de.modTime}, nil
+}
+func (de *mockDirEntry) Type() fs.FileMode { return fs.FileMode(0) } // Unused.
 
 // mockFileInfo provides a mock implementation of the fs.FileInfo interface for testing.
-// The Mode() method is the only relevant implementation for the tests.
+// The Mode(), Size(), and ModTime() methods are the only relevant implementations for the tests.
 type mockFileInfo struct {
-	mode fs.FileMode
+	mode    fs.FileMode
+	size    int64
+	modTime time.Time
 }
 
 func (fi *mockFileInfo) Mode() fs.FileMode  { return fi.mode }
-func (fi *mockFileInfo) Name() string       { return "" }          // Unused.
-func (fi *mockFileInfo) Size() int64        { return 0 }           // Unused.
-func (fi *mockFileInfo) ModTime() time.Time { return time.Time{} } // Unused.
-func (fi *mockFileInfo) IsDir() bool        { return false }       // Unused.
-func (fi *mockFileInfo) Sys() any           { return nil }         // Unused.
+func (fi *mockFileInfo) Name() string       { return "" } // Unused.
+func (fi *mockFileInfo) Size() int64        { return fi.size }
+func (fi *mockFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *mockFileInfo) IsDir() bool        { return false } // Unused.
+func (fi *mockFileInfo) Sys() any           { return nil }   // Unused.
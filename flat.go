@@ -0,0 +1,57 @@
+package main
+
+// treeCursor is the cursor position, scroll offset, and marks of a presentation of tree search
+// results, kept while the other presentation is shown.
+type treeCursor struct {
+	search string            // Search query the state belongs to.
+	idx    int               // Cursor position.
+	scroll int               // Scroll offset.
+	marks  map[string]*entry // Marked nodes by full path.
+}
+
+// flatSearchResults returns true if tree search results are shown as a flat list of matches
+// rather than as a filtered tree.
+func (m *model) flatSearchResults() bool {
+	return m.modeTree && m.modeSearchFlat && m.search != ""
+}
+
+// toggleSearchFlat switches tree search results between the filtered tree and the flat list. Both
+// present the same matches, but each keeps its own cursor, scroll offset, and marks for the
+// current search.
+func (m *model) toggleSearchFlat() {
+	m.modeSearchFlat = !m.modeSearchFlat
+	if !m.modeTree || m.search == "" {
+		return
+	}
+
+	shown := treeCursor{search: m.search, idx: m.treeIdx, scroll: m.scrollOffset, marks: m.treeMarks}
+	restored := m.searchHiddenCursor
+	m.searchHiddenCursor = shown
+	if restored.search != m.search || restored.marks == nil {
+		restored = treeCursor{marks: make(map[string]*entry)}
+	}
+
+	m.rebuildVisibleNodes()
+	m.treeIdx, m.scrollOffset, m.treeMarks = restored.idx, restored.scroll, restored.marks
+	m.modeMarks = len(m.treeMarks) != 0
+	if m.treeIdx >= len(m.visibleNodes) {
+		m.treeIdx = max(0, len(m.visibleNodes)-1)
+	}
+	m.adjustScrollOffset()
+}
+
+// searchResultNodes returns the visible nodes for the matches of a search: the matches with their
+// ancestors as a filtered tree or the matches in score order as a flat list.
+func (m *model) searchResultNodes(searchRoot *treeNode, matches []*treeNode) []*treeNode {
+	if !m.modeSearchFlat {
+		return buildFilteredTree(searchRoot, matches, m.nodeFilter())
+	}
+	filter := m.nodeFilter()
+	nodes := make([]*treeNode, 0, len(matches))
+	for _, node := range matches {
+		if node.entry != nil && !filter.skip(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
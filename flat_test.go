@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestToggleSearchFlat(t *testing.T) {
+	m, _ := newTestTree(t, "cmd/server/main.go", "internal/model.go", "README.md")
+	m.search = ".go"
+	m.rebuildVisibleNodes()
+
+	// The filtered tree shows the matches and their ancestors.
+	if len(m.visibleNodes) != 5 {
+		t.Fatalf("expected 5 nodes in the filtered tree, got %d", len(m.visibleNodes))
+	}
+	m.treeIdx = 4
+	m.toggleTreeMark()
+
+	// The flat list shows only the matches, with its own cursor and marks.
+	m.toggleSearchFlat()
+	if !m.flatSearchResults() {
+		t.Fatal("expected flat search results")
+	}
+	if len(m.visibleNodes) != 2 {
+		t.Fatalf("expected 2 nodes in the flat list, got %d", len(m.visibleNodes))
+	}
+	if m.treeIdx != 0 || m.modeMarks {
+		t.Fatalf("expected a new cursor without marks, got cursor %d and marks %v", m.treeIdx, m.treeMarks)
+	}
+	for _, node := range m.visibleNodes {
+		if node.entry.hasMode(entryModeDir) {
+			t.Fatalf("unexpected directory %s in the flat list", node.fullPath)
+		}
+	}
+	m.treeIdx = 1
+
+	// Switching back restores the cursor and marks of the filtered tree.
+	m.toggleSearchFlat()
+	if m.treeIdx != 4 || !m.markedTreeNode(4) {
+		t.Fatalf("expected the cursor and mark at 4, got cursor %d and marks %v", m.treeIdx, m.treeMarks)
+	}
+	m.toggleSearchFlat()
+	if m.treeIdx != 1 {
+		t.Fatalf("expected the flat list cursor at 1, got %d", m.treeIdx)
+	}
+
+	// Without a search, toggling only changes the mode.
+	m.search = ""
+	m.rebuildVisibleNodes()
+	m.treeIdx = 2
+	m.toggleSearchFlat()
+	if m.treeIdx != 2 || m.modeSearchFlat {
+		t.Fatalf("expected the cursor to stay at 2 with the flat list off, got %d", m.treeIdx)
+	}
+}
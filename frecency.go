@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/dkaslovsky/nav/internal/frecency"
+)
+
+func loadFrecency() (*frecency.DB, error) {
+	file, err := frecency.File()
+	if err != nil {
+		return nil, err
+	}
+	return frecency.Load(file)
+}
+
+// jumpPath returns the highest ranked existing directory matching the keywords.
+func jumpPath(keywords []string) (string, error) {
+	db, err := loadFrecency()
+	if err != nil {
+		return "", err
+	}
+	for _, e := range db.Match(keywords, time.Now()) {
+		if info, err := os.Stat(e.Path); err == nil && info.IsDir() {
+			return e.Path, nil
+		}
+	}
+	return "", fmt.Errorf("no visited directory matches %q", strings.Join(keywords, " "))
+}
+
+// recordFrecency adds the directories visited during the session and the directories of the
+// returned paths to the frecency database.
+func (m *model) recordFrecency() error {
+	paths := append([]string{}, m.visits...)
+	if m.exit != nil {
+		for _, path := range m.exit.paths {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				path = filepath.Dir(path)
+			}
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	file, err := frecency.File()
+	if err != nil {
+		return err
+	}
+	return frecency.Add(file, time.Now(), paths...)
+}
+
+// frecencyPicker lists the visited directories, highest ranked first.
+type frecencyPicker struct {
+	listView
+	entries []frecency.Entry
+}
+
+func (m *model) openFrecency() {
+	m.modeFrecency = true
+	m.frecency = &frecencyPicker{listView: listView{title: " Frecent directories"}}
+	if err := m.loadFrecencyPicker(); err != nil {
+		m.setError(err, "failed to load frecent directories")
+	}
+}
+
+func (m *model) closeFrecency() {
+	m.modeFrecency = false
+	m.frecency = nil
+}
+
+func (m *model) loadFrecencyPicker() error {
+	db, err := loadFrecency()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	entries := []frecency.Entry{}
+	lines := []string{}
+	for _, e := range db.Sorted(now) {
+		if info, err := os.Stat(e.Path); err != nil || !info.IsDir() {
+			continue
+		}
+		entries = append(entries, e)
+		lines = append(lines, fmt.Sprintf("%8.1f    %s", e.Score(now), sanitizePreviewLine(substituteHomeDir(e.Path))))
+	}
+	m.frecency.entries = entries
+	m.frecency.setLines(lines)
+	return nil
+}
+
+func actionModeFrecency(m *model, msg tea.KeyMsg, esc bool) actionResult {
+	switch {
+
+	case esc || key.Matches(msg, keyEsc) || key.Matches(msg, keyModeFrecency):
+		m.closeFrecency()
+
+	case key.Matches(msg, keyUp):
+		m.frecency.moveUp()
+
+	case key.Matches(msg, keyDown):
+		m.frecency.moveDown()
+
+	case key.Matches(msg, keySelect):
+		if m.frecency.idx < len(m.frecency.entries) {
+			path := m.frecency.entries[m.frecency.idx].Path
+			m.closeFrecency()
+			return newActionResult(m.jumpTo(path))
+		}
+
+	case key.Matches(msg, keyDelete):
+		if m.frecency.idx < len(m.frecency.entries) {
+			m.promptRemoveFrecency(m.frecency.entries[m.frecency.idx].Path)
+		}
+
+	}
+
+	// Unconditional return to disable all other functionality.
+	return newActionResult(m.indexingCmd())
+}
+
+// promptRemoveFrecency asks for confirmation before removing a directory from the frecency
+// database.
+func (m *model) promptRemoveFrecency(path string) {
+	m.setPrompt(&prompt{
+		label:   fmt.Sprintf("forget %s", substituteHomeDir(path)),
+		confirm: true,
+		action: func(m *model, _ string) (string, error) {
+			file, err := frecency.File()
+			if err != nil {
+				return "", err
+			}
+			return "", frecency.Remove(file, path)
+		},
+		status: "failed to forget directory",
+	})
+}
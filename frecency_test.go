@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordFrecency(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+
+	src, docs := filepath.Join(root, "src"), filepath.Join(root, "docs")
+	for _, dir := range []string{src, docs} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(docs, "notes.md")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newModel()
+	m.visits = []string{src, docs}
+	m.setExit(file)
+	if err := m.recordFrecency(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The directory of a returned file is recorded, ranking docs above src.
+	path, err := jumpPath([]string{filepath.Base(root)})
+	if err == nil {
+		t.Fatalf("expected no match for a keyword outside the last path component, got %s", path)
+	}
+	if path, err = jumpPath([]string{""}); err != nil || path != docs {
+		t.Fatalf("expected %s, got %s (%v)", docs, path, err)
+	}
+	if path, err = jumpPath([]string{"sr"}); err != nil || path != src {
+		t.Fatalf("expected %s, got %s (%v)", src, path, err)
+	}
+
+	// Directories that no longer exist are skipped.
+	if err := os.Remove(src); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jumpPath([]string{"sr"}); err == nil {
+		t.Fatal("expected no match for a removed directory")
+	}
+}
+
+func TestParseJumpArgs(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+
+	src, docs := filepath.Join(root, "src"), filepath.Join(root, "docs")
+	for _, dir := range []string{src, docs} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := newModel()
+	m.visits = []string{src, docs}
+	if err := m.recordFrecency(); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(root)
+
+	tests := map[string]struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		"keywords": {
+			args: []string{flagJump, filepath.Base(root), "sr"},
+			want: src,
+		},
+		"keywords_end_at_flag": {
+			args: []string{flagJump, "sr", flagHidden},
+			want: src,
+		},
+		// An argument that is an existing path relative to the working directory is a keyword.
+		"keyword_existing_path": {
+			args: []string{flagJump, filepath.Base(root), "src"},
+			want: src,
+		},
+		"directory_after_jump": {
+			args:    []string{flagJump, "sr", flagHidden, docs},
+			wantErr: true,
+		},
+		"directory_before_jump": {
+			args:    []string{docs, flagJump, "sr"},
+			wantErr: true,
+		},
+		"no_keywords": {
+			args:    []string{flagJump, flagHidden},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			m := newModel()
+			err := parseArgs(test.args, m)
+			if test.wantErr {
+				if err == nil {
+					tt.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if m.path != test.want {
+				tt.Fatalf("expected %s, got %s", test.want, m.path)
+			}
+		})
+	}
+}
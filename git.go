@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/dkaslovsky/nav/internal/gitignore"
+	"github.com/dkaslovsky/nav/internal/gitstatus"
+)
+
+// gitState tracks the status of the git repository containing the current directory.
+type gitState struct {
+	path   string // Directory for which the repository was last resolved.
+	root   string // Root of the repository, or empty outside of a repository.
+	status *gitstatus.Status
+	stale  bool // The status must be reloaded because entries were modified.
+}
+
+// gitStatusMsg delivers the status loaded for the repository at root.
+type gitStatusMsg struct {
+	root   string
+	status *gitstatus.Status
+	err    error
+}
+
+// refreshGitStatus returns a command to load the status of the repository containing the current
+// directory when it has not been loaded or is stale. The status is loaded once per repository
+// rather than per directory, so navigating within a repository does not run git.
+func (m *model) refreshGitStatus() tea.Cmd {
+	if !m.modeGit {
+		return nil
+	}
+	g := &m.git
+	if g.path == m.path && !g.stale {
+		return nil
+	}
+	g.path = m.path
+
+	root, ok := gitignore.FindRoot(m.path)
+	if !ok {
+		g.root, g.status, g.stale = "", nil, false
+		return nil
+	}
+	if root == g.root && !g.stale {
+		return nil
+	}
+	if root != g.root {
+		g.status = nil
+	}
+	g.root, g.stale = root, false
+
+	return func() tea.Msg {
+		status, err := gitstatus.Load(root)
+		return gitStatusMsg{root: root, status: status, err: err}
+	}
+}
+
+// invalidateGitStatus marks the status for reloading after entries are modified.
+func (m *model) invalidateGitStatus() {
+	m.git.stale = true
+}
+
+// gitBranch returns the branch of the repository containing the current directory, if known.
+func (m *model) gitBranch() string {
+	if !m.modeGit || m.git.status == nil {
+		return ""
+	}
+	return m.git.status.Branch
+}
+
+// gitBranchBar renders the branch for the location bar.
+func (m *model) gitBranchBar() string {
+	branch := m.gitBranch()
+	if branch == "" {
+		return ""
+	}
+	return gitRendererBranch.Render(" " + branch + " ")
+}
+
+// gitMarker returns the marker and style for the most significant flag of a state.
+func gitMarker(state gitstatus.State) (string, *lipgloss.Style) {
+	switch {
+	case state.Has(gitstatus.Conflicted):
+		return "U", &gitRendererConflicted
+	case state.Has(gitstatus.Changes):
+		return "•", &gitRendererChanges
+	case state.Has(gitstatus.Modified):
+		return "M", &gitRendererModified
+	case state.Has(gitstatus.Staged):
+		return "+", &gitRendererStaged
+	case state.Has(gitstatus.Untracked):
+		return "?", &gitRendererUntracked
+	case state.Has(gitstatus.Ignored):
+		return "!", &gitRendererIgnored
+	}
+	return "", nil
+}
+
+// displayNameWithGitStatus appends a marker for the git state of an entry in the directory at path.
+func displayNameWithGitStatus(status *gitstatus.Status, path string) displayNameOption {
+	return func(c *displayNameConfig, mode entryMode, info fs.FileInfo) {
+		state := status.Lookup(filepath.Join(path, c.name), mode.has(entryModeDir))
+		if marker, style := gitMarker(state); marker != "" {
+			c.gitMarker = " " + style.Render(marker)
+			c.gitMarkerLen = 1 + len([]rune(marker))
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// history is the list of directories visited during the session and the position of the current
+// directory in it, which moves back and forward like the history of a web browser.
+type history struct {
+	paths []string
+	idx   int
+}
+
+// push adds path after the current position, replacing the forward history. Pushing the current
+// path has no effect.
+func (h *history) push(path string) {
+	if len(h.paths) == 0 {
+		h.paths = []string{path}
+		h.idx = 0
+		return
+	}
+	if h.paths[h.idx] == path {
+		return
+	}
+	// Limit the capacity so that append copies and a saved history keeps its forward paths.
+	h.paths = append(h.paths[:h.idx+1:h.idx+1], path)
+	h.idx = len(h.paths) - 1
+}
+
+// jumpTo changes the current directory to path as a new step in the history.
+func (m *model) jumpTo(path string) tea.Cmd {
+	m.saveCursor()
+	m.setPath(path)
+	return m.showPath()
+}
+
+// jumpToEntry changes into path if it is a directory and otherwise to the directory containing
+// path with the cursor on it.
+func (m *model) jumpToEntry(path string) tea.Cmd {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return m.jumpTo(path)
+	}
+
+	dir := filepath.Dir(path)
+	cache, ok := m.pathCache[dir]
+	if !ok {
+		cache = newCacheItem()
+		m.pathCache[dir] = cache
+	}
+	cache.setCursorName(filepath.Base(path))
+	cache.setTreeCursorPath(path)
+	return m.jumpTo(dir)
+}
+
+// moveHistory changes to the directory delta steps forward, or back for a negative delta, in the
+// history.
+func (m *model) moveHistory(delta int) tea.Cmd {
+	idx := m.history.idx + delta
+	if idx < 0 || idx >= len(m.history.paths) {
+		return nil
+	}
+	return m.goToHistory(idx)
+}
+
+// goToHistory changes to the directory at position idx of the history without changing the
+// history itself.
+func (m *model) goToHistory(idx int) tea.Cmd {
+	h := m.history
+	m.saveCursor()
+	m.setPath(h.paths[idx])
+	m.history = h
+	m.history.idx = idx
+	return m.showPath()
+}
+
+// showPath lists the directory set with setPath in the current view mode, clearing the search and
+// restoring the cursor saved for the directory. The previous directory is restored if the directory
+// cannot be read.
+func (m *model) showPath() tea.Cmd {
+	m.clearSearch()
+
+	var cmd tea.Cmd
+	var err error
+	if m.modeTree {
+		err, cmd = m.listTree()
+	} else {
+		err = m.list()
+	}
+	if err != nil {
+		m.restorePath()
+		if m.modeTree {
+			m.rebuildVisibleNodes()
+		}
+		m.setError(err, "failed to read directory")
+		return nil
+	}
+
+	if m.modeTree {
+		m.restoreTreeCursor()
+	}
+	return cmd
+}
+
+// historyPicker lists the history, most recent first, with the cursor on the current directory.
+type historyPicker struct {
+	listView
+	idxs []int // History position of each line.
+}
+
+func (m *model) openHistory() {
+	m.modeHistory = true
+	m.historyView = &historyPicker{listView: listView{title: " History"}}
+	m.loadHistoryPicker()
+}
+
+func (m *model) closeHistory() {
+	m.modeHistory = false
+	m.historyView = nil
+}
+
+func (m *model) loadHistoryPicker() {
+	paths := m.history.paths
+	if len(paths) == 0 {
+		paths = []string{m.path}
+	}
+
+	lines := make([]string, 0, len(paths))
+	idxs := make([]int, 0, len(paths))
+	for i := len(paths) - 1; i >= 0; i-- {
+		current := " "
+		if i == m.history.idx {
+			current = "*"
+			m.historyView.idx = len(lines)
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s", current, sanitizePreviewLine(substituteHomeDir(paths[i]))))
+		idxs = append(idxs, i)
+	}
+	m.historyView.idxs = idxs
+	m.historyView.setLines(lines)
+}
+
+func actionModeHistory(m *model, msg tea.KeyMsg, esc bool) actionResult {
+	switch {
+
+	case esc || key.Matches(msg, keyEsc) || key.Matches(msg, keyModeHistory):
+		m.closeHistory()
+
+	case key.Matches(msg, keyUp):
+		m.historyView.moveUp()
+
+	case key.Matches(msg, keyDown):
+		m.historyView.moveDown()
+
+	case key.Matches(msg, keySelect):
+		idx := m.historyView.idxs[m.historyView.idx]
+		m.closeHistory()
+		if len(m.history.paths) == 0 || idx == m.history.idx {
+			return newActionResult(m.indexingCmd())
+		}
+		return newActionResult(tea.Batch(m.goToHistory(idx), m.indexingCmd()))
+
+	}
+
+	// Unconditional return to disable all other functionality.
+	return newActionResult(m.indexingCmd())
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistoryPush(t *testing.T) {
+	h := history{}
+	for _, path := range []string{"/a", "/b", "/b", "/c"} {
+		h.push(path)
+	}
+	if want := []string{"/a", "/b", "/c"}; !reflect.DeepEqual(h.paths, want) || h.idx != 2 {
+		t.Fatalf("expected history %v at 2, got %v at %d", want, h.paths, h.idx)
+	}
+
+	// Pushing after going back replaces the forward history without changing a saved copy.
+	h.idx = 0
+	saved := h
+	h.push("/d")
+	if want := []string{"/a", "/d"}; !reflect.DeepEqual(h.paths, want) || h.idx != 1 {
+		t.Fatalf("expected history %v at 1, got %v at %d", want, h.paths, h.idx)
+	}
+	if want := []string{"/a", "/b", "/c"}; !reflect.DeepEqual(saved.paths, want) {
+		t.Fatalf("expected saved history %v, got %v", want, saved.paths)
+	}
+}
+
+func TestMoveHistory(t *testing.T) {
+	m, dir := newTestTree(t, "a/file", "b/file")
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+
+	m.treeIdx = 1
+	m.jumpTo(a)
+	m.jumpTo(b)
+
+	// Going back restores the tree cursor saved for the directory.
+	m.moveHistory(-1)
+	m.moveHistory(-1)
+	if m.path != dir || m.treeIdx != 1 {
+		t.Fatalf("expected %s with the cursor at 1, got %s at %d", dir, m.path, m.treeIdx)
+	}
+	m.moveHistory(-1)
+	if m.path != dir {
+		t.Fatalf("expected to stay at the start of the history, got %s", m.path)
+	}
+
+	m.moveHistory(1)
+	if m.path != a {
+		t.Fatalf("expected %s, got %s", a, m.path)
+	}
+
+	// A directory that cannot be read leaves the history unchanged.
+	if err := os.RemoveAll(b); err != nil {
+		t.Fatal(err)
+	}
+	m.moveHistory(1)
+	if m.path != a || m.history.idx != 1 || !m.modeError {
+		t.Fatalf("expected to stay at %s with an error, got %s at %d", a, m.path, m.history.idx)
+	}
+
+	// A jump to an entry in the current directory only moves the cursor.
+	m.clearError()
+	m.jumpToEntry(filepath.Join(dir, "a", "file"))
+	if want := []string{dir, a, b}; !reflect.DeepEqual(m.history.paths, want) || m.path != a {
+		t.Fatalf("expected history %v at %s, got %v at %s", want, a, m.history.paths, m.path)
+	}
+
+	// A jump is a new step that replaces the forward history.
+	m.jumpTo(dir)
+	if want := []string{dir, a, dir}; !reflect.DeepEqual(m.history.paths, want) {
+		t.Fatalf("expected history %v, got %v", want, m.history.paths)
+	}
+}
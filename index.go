@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/dkaslovsky/nav/internal/indexcache"
+)
+
+// indexCacheLimit is the limit on the total size of the search indexes cached on disk.
+const indexCacheLimit = 256 << 20
+
+// indexValidatedMsg reports the directories listed from the search index cache that changed since
+// they were cached.
+type indexValidatedMsg struct {
+	changed    []changedDir
+	generation int64 // generation counter to detect stale messages
+}
+
+// changedDir is a directory that changed since it was cached, with its current file info or nil if
+// it no longer exists.
+type changedDir struct {
+	path string
+	info fs.FileInfo
+}
+
+// indexCache is the on-disk cache of the search index of a tree root. The index loader lists the
+// directories it has from the cache instead of reading them, and once the index is loaded those
+// directories are validated by their modification times so that only the changed ones are read.
+type indexCache struct {
+	file    string
+	root    *treeNode
+	rebuild bool // Ignore the cached directories, only saving the index.
+
+	// Written by the index loader and read once it is done.
+	dirs   map[string]indexcache.Dir // Cached directories by path, dropped once the index is loaded.
+	listed []listedDir               // Directories listed from the cache.
+	read   bool                      // Some directories were not cached and were read from disk.
+	saved  map[string]indexcache.Dir // Listings of the indexed directories, handed over once saved.
+}
+
+// listedDir is a directory listed from the cache with its modification time when it was cached.
+type listedDir struct {
+	path    string
+	modTime int64
+}
+
+// newIndexCache returns the cache of the search index of root, or nil if caching is off.
+func (m *model) newIndexCache(root *treeNode) *indexCache {
+	if !m.modeIndexCache {
+		return nil
+	}
+	file, err := indexcache.File(root.fullPath)
+	if err != nil {
+		return nil
+	}
+	return &indexCache{file: file, root: root, rebuild: m.rebuildIndex}
+}
+
+// load reads the cached directories until ctx is canceled. Caching is best effort: a cache that
+// cannot be read holds no directories, so that the index is read from disk.
+func (c *indexCache) load(ctx context.Context) {
+	if c == nil || c.rebuild {
+		return
+	}
+	if index, err := indexcache.Load(ctx, c.file, c.root.fullPath); err == nil {
+		c.dirs = index.Dirs
+	}
+}
+
+// loadChildren populates the children of the node from the cache, returning false if its directory
+// is not cached. The sizes and times of the cached entries are only validated through their
+// directory, so they are read again when the order uses them and otherwise once displayed.
+func (c *indexCache) loadChildren(n *treeNode, order sortOrder) bool {
+	if c == nil {
+		return false
+	}
+	dir, ok := c.dirs[n.fullPath]
+	if !ok {
+		c.read = true
+		return false
+	}
+
+	entries := make([]*entry, 0, len(dir.Entries))
+	for _, cached := range dir.Entries {
+		dirEntry := cached.DirEntry()
+		if order.usesInfo() {
+			if info, err := os.Lstat(filepath.Join(n.fullPath, cached.Name)); err == nil {
+				dirEntry = fs.FileInfoToDirEntry(info)
+			}
+		}
+		ent, err := newEntry(dirEntry)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, ent)
+	}
+	n.setChildren(entries, order)
+	for _, child := range n.children {
+		child.stale = !order.usesInfo()
+	}
+	c.listed = append(c.listed, listedDir{path: n.fullPath, modTime: dir.ModTime})
+	return true
+}
+
+// record adds the listing of a loaded directory to the directories saved with the index. The
+// virtual root is always read from disk and is not cached.
+func (c *indexCache) record(n *treeNode) {
+	if c == nil || !n.loaded || n.entry == nil || n.entry.info == nil {
+		return
+	}
+	entries := make([]indexcache.Entry, 0, len(n.children))
+	for _, child := range n.children {
+		if child.entry.info != nil {
+			entries = append(entries, indexcache.NewEntry(child.entry.info))
+		}
+	}
+	if c.saved == nil {
+		c.saved = make(map[string]indexcache.Dir)
+	}
+	c.saved[n.fullPath] = indexcache.Dir{ModTime: n.entry.info.ModTime().UnixNano(), Entries: entries}
+}
+
+// refreshVisibleInfos reads the file infos of the rows on screen that were listed from the cache.
+// The index loader reads the entries it walks, so they are refreshed once it is done.
+func (m *model) refreshVisibleInfos() {
+	if !m.modeTree || m.searchIndexLoading {
+		return
+	}
+	start := min(m.scrollOffset, len(m.visibleNodes))
+	end := min(start+max(m.height-3, 0), len(m.visibleNodes))
+	for _, node := range m.visibleNodes[start:end] {
+		node.refreshInfo()
+	}
+}
+
+// validateIndexCache returns a command that checks the modification times of the directories the
+// index loader listed from the cache or, if it listed none, saves the index when it read any
+// directories from disk.
+func (m *model) validateIndexCache() tea.Cmd {
+	// A restarted index loader validates the cache once it is done, and a cache without recorded
+	// directories has nothing to save or has already been saved.
+	c := m.searchIndexCache
+	if c == nil || c.root != m.searchIndexRoot || m.searchIndexLoading || c.saved == nil {
+		return nil
+	}
+	if len(c.listed) == 0 {
+		if !c.read {
+			return nil
+		}
+		return m.saveIndexCache()
+	}
+
+	gen, listed := m.searchIndexGeneration, c.listed
+	return func() tea.Msg {
+		var changed []changedDir
+		for _, dir := range listed {
+			info, err := os.Lstat(dir.path)
+			if err != nil {
+				changed = append(changed, changedDir{path: dir.path})
+			} else if info.ModTime().UnixNano() != dir.modTime {
+				changed = append(changed, changedDir{path: dir.path, info: info})
+			}
+		}
+		return indexValidatedMsg{changed: changed, generation: gen}
+	}
+}
+
+// applyIndexValidation reads the changed directories again, updating the search index, and saves
+// the index if it differs from the cache.
+func (m *model) applyIndexValidation(changed []changedDir) tea.Cmd {
+	c := m.searchIndexCache
+	if c == nil || c.root != m.searchIndexRoot || c.root != m.treeRoot {
+		return nil
+	}
+	if len(changed) == 0 && !c.read {
+		return nil
+	}
+
+	// Changed directories are listed by their parents with the modification times they had when
+	// they were cached, which are replaced so that the saved index records the current ones.
+	dirs := make([]string, 0, len(changed))
+	for _, dir := range changed {
+		if node := c.root.find(dir.path); node != nil && dir.info != nil && dir.info.IsDir() {
+			if ent, err := newEntry(fs.FileInfoToDirEntry(dir.info)); err == nil {
+				node.entry, node.stale = ent, false
+			}
+		}
+		dirs = append(dirs, dir.path)
+	}
+	cmd := m.refreshTreeDirs(dirs)
+	if m.searchIndexLoading {
+		// The index loader was restarted to index the added entries and walks the tree.
+		return cmd
+	}
+
+	// Only the listings of the changed directories, and of their parents listing them, are
+	// recorded again.
+	for _, dir := range changed {
+		node := c.root.find(dir.path)
+		if node == nil {
+			delete(c.saved, dir.path)
+			continue
+		}
+		c.record(node)
+		if node.parent != nil {
+			c.record(node.parent)
+		}
+	}
+	return tea.Batch(cmd, m.saveIndexCache())
+}
+
+// saveIndexCache returns a command that saves the listings of the directories recorded by the index
+// loader to the cache. The listings are handed over to the command, as the index is saved once.
+func (m *model) saveIndexCache() tea.Cmd {
+	c := m.searchIndexCache
+	index := &indexcache.Index{Root: c.root.fullPath, Dirs: c.saved}
+	c.saved = nil
+	return func() tea.Msg {
+		// Caching is best effort: an index that cannot be saved is read from disk next time.
+		_ = indexcache.Save(c.file, index, indexCacheLimit)
+		return nil
+	}
+}
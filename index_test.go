@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestIndexCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "a/x", "b/c/y")
+
+	// load starts tree view mode in dir with the cache, returning the command that validates it.
+	load := func(rebuild bool) (*model, tea.Cmd) {
+		m := newModel()
+		m.rebuildIndex = rebuild
+		return m, loadTestTree(t, m, dir)
+	}
+	indexed := func(m *model) []string {
+		names := append([]string{}, m.searchIndexNames...)
+		sort.Strings(names)
+		return names
+	}
+
+	// Without a cache the tree is read from disk and saved.
+	m, cmd := load(false)
+	if len(m.searchIndexCache.listed) != 0 || !m.searchIndexCache.read {
+		t.Fatalf("expected no directories listed from the cache, got %v", m.searchIndexCache.listed)
+	}
+	if msg := cmd(); msg != nil {
+		t.Fatalf("expected the index to be saved, got %T", msg)
+	}
+	if _, err := os.Stat(m.searchIndexCache.file); err != nil {
+		t.Fatal(err)
+	}
+
+	// With the cache the directories are listed without reading them, so that a file added since
+	// is only indexed once its changed directory is read again.
+	writeTestFiles(t, dir, "a/z")
+	m, cmd = load(false)
+	if len(m.searchIndexCache.listed) != 3 || m.searchIndexCache.read {
+		t.Fatalf("expected all directories listed from the cache, got %v", m.searchIndexCache.listed)
+	}
+	if want := []string{"a", "b", "c", "x", "y"}; !reflect.DeepEqual(indexed(m), want) {
+		t.Fatalf("expected indexed names %v, got %v", want, indexed(m))
+	}
+	msg, ok := cmd().(indexValidatedMsg)
+	if !ok {
+		t.Fatalf("expected the cache to be validated, got %T", msg)
+	}
+	if len(msg.changed) != 1 || msg.changed[0].path != filepath.Join(dir, "a") {
+		t.Fatalf("expected only a to have changed, got %v", msg.changed)
+	}
+	_, cmd = m.update(msg)
+	if want := []string{"a", "b", "c", "x", "y", "z"}; !reflect.DeepEqual(indexed(m), want) {
+		t.Fatalf("expected indexed names %v, got %v", want, indexed(m))
+	}
+	if cmd == nil || cmd() != nil {
+		t.Fatal("expected the validated index to be saved")
+	}
+
+	// The saved index records the current state, so that nothing has changed the next time.
+	m, cmd = load(false)
+	if msg, ok := cmd().(indexValidatedMsg); !ok || len(msg.changed) != 0 {
+		t.Fatalf("expected no changed directories, got %v", msg.changed)
+	}
+	if want := []string{"a", "b", "c", "x", "y", "z"}; !reflect.DeepEqual(indexed(m), want) {
+		t.Fatalf("expected indexed names %v, got %v", want, indexed(m))
+	}
+
+	// Sizes of files listed from the cache, which do not change their directories, are read again
+	// when the files are displayed, or sorted by size once their directory is expanded.
+	if err := os.WriteFile(filepath.Join(dir, "a", "x"), []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	size := func(m *model) int64 {
+		return m.treeRoot.find(filepath.Join(dir, "a", "x")).entry.info.Size()
+	}
+	m, _ = load(false)
+	if size(m) != 0 {
+		t.Fatalf("expected the cached size, got %d", size(m))
+	}
+	expand := func(m *model) {
+		for i, node := range m.visibleNodes {
+			if node.fullPath == filepath.Join(dir, "a") {
+				m.treeIdx = i
+			}
+		}
+		m.treeToggleExpand()
+	}
+	expand(m)
+	if size(m) != 0 {
+		t.Fatalf("expected the cached size before the file is displayed, got %d", size(m))
+	}
+	m.Update(nil)
+	if size(m) != 4 {
+		t.Fatalf("expected the size of the displayed file to be read, got %d", size(m))
+	}
+	m, _ = load(false)
+	m.sort.mode = sortModeSize
+	m.resort()
+	if size(m) != 0 {
+		t.Fatalf("expected the cached size in a collapsed directory, got %d", size(m))
+	}
+	expand(m)
+	if size(m) != 4 {
+		t.Fatalf("expected the size to be read when sorting by size, got %d", size(m))
+	}
+	m, _ = load(false)
+	expand(m)
+	m.sort.mode = sortModeSize
+	m.resort()
+	if size(m) != 4 {
+		t.Fatalf("expected the size in an expanded directory to be read when sorting by size, got %d", size(m))
+	}
+
+	// Rebuilding reads the tree from disk.
+	m, _ = load(true)
+	if len(m.searchIndexCache.listed) != 0 || !m.searchIndexCache.read {
+		t.Fatalf("expected no directories listed from the cache, got %v", m.searchIndexCache.listed)
+	}
+}
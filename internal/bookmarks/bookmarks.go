@@ -0,0 +1,164 @@
+// Package bookmarks stores named directories in a file with one bookmark per line, each a name and
+// an absolute path separated by a tab. Blank lines and lines starting with "#" are ignored.
+package bookmarks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dkaslovsky/nav/internal/xdg"
+)
+
+// Bookmark is a named directory.
+type Bookmark struct {
+	Name string
+	Path string
+}
+
+// File returns the path of the bookmarks file, $XDG_DATA_HOME/nav/bookmarks.
+func File() (string, error) {
+	dir, err := xdg.DataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "nav", "bookmarks"), nil
+}
+
+// Bookmarks is an ordered list of bookmarks read from a file.
+type Bookmarks struct {
+	file string
+	list []Bookmark
+}
+
+// Load reads the bookmarks from file. A file that does not exist holds no bookmarks.
+func Load(file string) (*Bookmarks, error) {
+	b := &Bookmarks{file: file}
+
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, path, found := strings.Cut(line, "\t")
+		if !found || ValidName(name) != nil || !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("%s:%d: invalid bookmark %q", file, n, line)
+		}
+		b.list = append(b.list, Bookmark{Name: name, Path: path})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// List returns the bookmarks in the order they were added.
+func (b *Bookmarks) List() []Bookmark {
+	return append([]Bookmark{}, b.list...)
+}
+
+// Get returns the bookmark with the given name.
+func (b *Bookmarks) Get(name string) (Bookmark, bool) {
+	for _, bookmark := range b.list {
+		if bookmark.Name == name {
+			return bookmark, true
+		}
+	}
+	return Bookmark{}, false
+}
+
+// Add adds a bookmark to the end of the list or, if a bookmark with the name exists, replaces its
+// path in place. The path must be absolute.
+func (b *Bookmarks) Add(name string, path string) error {
+	if err := ValidName(name); err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("bookmark path %q is not absolute", path)
+	}
+	if strings.ContainsAny(path, "\r\n") {
+		return fmt.Errorf("bookmark path %q contains a line break", path)
+	}
+
+	path = filepath.Clean(path)
+	for i := range b.list {
+		if b.list[i].Name == name {
+			b.list[i].Path = path
+			return nil
+		}
+	}
+	b.list = append(b.list, Bookmark{Name: name, Path: path})
+	return nil
+}
+
+// Remove removes the bookmark with the given name.
+func (b *Bookmarks) Remove(name string) error {
+	for i := range b.list {
+		if b.list[i].Name == name {
+			b.list = append(b.list[:i], b.list[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no bookmark named %q", name)
+}
+
+// Save writes the bookmarks to the file, creating its directory if needed. The file is replaced
+// atomically so that a concurrent reader never sees a partial list.
+func (b *Bookmarks) Save() error {
+	dir := filepath.Dir(b.file)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	var content strings.Builder
+	for _, bookmark := range b.list {
+		fmt.Fprintf(&content, "%s\t%s\n", bookmark.Name, bookmark.Path)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(b.file)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(content.String()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), b.file); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// ValidName returns an error if name cannot be stored as the name of a bookmark.
+func ValidName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("bookmark name is empty")
+	case strings.TrimSpace(name) != name:
+		return fmt.Errorf("bookmark name %q has leading or trailing space", name)
+	case strings.HasPrefix(name, "#"):
+		return fmt.Errorf("bookmark name %q starts with #", name)
+	case strings.ContainsAny(name, "\t\r\n"):
+		return fmt.Errorf("bookmark name %q contains a tab or line break", name)
+	}
+	return nil
+}
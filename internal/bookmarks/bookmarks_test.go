@@ -0,0 +1,135 @@
+package bookmarks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", root)
+
+	file, err := File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "nav", "bookmarks"); file != want {
+		t.Fatalf("expected file %s, got %s", want, file)
+	}
+
+	// A missing file holds no bookmarks.
+	b, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.List()) != 0 {
+		t.Fatalf("expected no bookmarks, got %v", b.List())
+	}
+
+	src, docs := filepath.Join(root, "src"), filepath.Join(root, "my docs", "tab\there")
+	for _, bookmark := range []Bookmark{{"src", src}, {"docs", docs}, {"src", src + "/../src2"}} {
+		if err := b.Add(bookmark.Name, bookmark.Path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Bookmark{{"src", filepath.Join(root, "src2")}, {"docs", docs}}
+	if !reflect.DeepEqual(loaded.List(), want) {
+		t.Fatalf("expected bookmarks %v, got %v", want, loaded.List())
+	}
+	if bookmark, ok := loaded.Get("docs"); !ok || bookmark.Path != docs {
+		t.Fatalf("expected docs bookmark, got %v", bookmark)
+	}
+
+	if err := loaded.Remove("src"); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Remove("src"); err == nil {
+		t.Fatal("expected error removing a missing bookmark")
+	}
+	if _, ok := loaded.Get("src"); ok {
+		t.Fatal("expected src bookmark to be removed")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    []Bookmark
+		wantErr bool
+	}{
+		"comments_and_blank_lines": {
+			content: "# bookmarks\n\nhome\t/home/user\r\n",
+			want:    []Bookmark{{"home", "/home/user"}},
+		},
+		"missing_tab": {
+			content: "home /home/user\n",
+			wantErr: true,
+		},
+		"relative_path": {
+			content: "home\thome/user\n",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			if filepath.Separator != '/' && test.want != nil {
+				tt.Skip("paths in the fixture are not absolute")
+			}
+			file := filepath.Join(tt.TempDir(), "bookmarks")
+			if err := os.WriteFile(file, []byte(test.content), 0o644); err != nil {
+				tt.Fatal(err)
+			}
+			b, err := Load(file)
+			if test.wantErr {
+				if err == nil {
+					tt.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if !reflect.DeepEqual(b.List(), test.want) {
+				tt.Fatalf("expected bookmarks %v, got %v", test.want, b.List())
+			}
+		})
+	}
+}
+
+func TestAddInvalid(t *testing.T) {
+	abs := filepath.Join(t.TempDir(), "dir")
+
+	tests := map[string]struct {
+		name string
+		path string
+	}{
+		"empty_name":      {name: "", path: abs},
+		"padded_name":     {name: " a", path: abs},
+		"comment_name":    {name: "#a", path: abs},
+		"tab_in_name":     {name: "a\tb", path: abs},
+		"relative_path":   {name: "a", path: "dir"},
+		"newline_in_path": {name: "a", path: abs + "\nx"},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			b := &Bookmarks{}
+			if err := b.Add(test.name, test.path); err == nil {
+				tt.Fatalf("expected error adding %q: %q", test.name, test.path)
+			}
+		})
+	}
+}
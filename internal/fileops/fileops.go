@@ -0,0 +1,174 @@
+// Package fileops implements the file operations available from the interface. Operations never
+// overwrite existing entries.
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrExists is returned when the destination of an operation already exists.
+var ErrExists = errors.New("destination already exists")
+
+// ValidName returns an error if name cannot be used as the name of a new entry in a directory.
+func ValidName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("name must not be empty")
+	case name == "." || name == "..":
+		return fmt.Errorf("invalid name %q", name)
+	case strings.ContainsRune(name, filepath.Separator) || strings.ContainsRune(name, '/'):
+		return fmt.Errorf("name %q must not contain a path separator", name)
+	}
+	return nil
+}
+
+// CreateFile creates an empty file at path.
+func CreateFile(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%s: %w", path, ErrExists)
+		}
+		return err
+	}
+	return f.Close()
+}
+
+// Mkdir creates a directory at path.
+func Mkdir(path string) error {
+	err := os.Mkdir(path, 0o777)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s: %w", path, ErrExists)
+	}
+	return err
+}
+
+// Rename renames the entry at src to dst.
+func Rename(src string, dst string) error {
+	if err := checkNotExists(dst); err != nil {
+		return err
+	}
+	return os.Rename(src, dst)
+}
+
+// Copy recursively copies the entry at src to dst. Symlinks are copied as symlinks and file
+// permissions are preserved.
+func Copy(src string, dst string) error {
+	if err := checkNotExists(dst); err != nil {
+		return err
+	}
+	if err := checkNotWithin(src, dst); err != nil {
+		return err
+	}
+	return copyEntry(src, dst)
+}
+
+// Move moves the entry at src to dst, copying and removing the source when it cannot be renamed
+// across filesystems.
+func Move(src string, dst string) error {
+	if err := checkNotExists(dst); err != nil {
+		return err
+	}
+	if err := checkNotWithin(src, dst); err != nil {
+		return err
+	}
+
+	err := os.Rename(src, dst)
+	var linkErr *os.LinkError
+	if err == nil || !errors.As(err, &linkErr) || !isCrossDevice(linkErr.Err) {
+		return err
+	}
+
+	if err := copyEntry(src, dst); err != nil {
+		// Do not leave a partial copy behind.
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+func copyEntry(src string, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+
+	case info.IsDir():
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		if err := os.Mkdir(dst, info.Mode().Perm()|0o700); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := copyEntry(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+				return err
+			}
+		}
+		// Restore the source permissions, which may not allow writing the copied contents.
+		return os.Chmod(dst, info.Mode().Perm())
+
+	case info.Mode().IsRegular():
+		return copyFile(src, dst, info.Mode().Perm())
+
+	default:
+		return fmt.Errorf("%s: cannot copy special file", src)
+	}
+}
+
+func copyFile(src string, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func checkNotExists(path string) error {
+	_, err := os.Lstat(path)
+	if err == nil {
+		return fmt.Errorf("%s: %w", path, ErrExists)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// checkNotWithin returns an error if dst is inside the directory src, which would copy or move a
+// directory into itself.
+func checkNotWithin(src string, dst string) error {
+	rel, err := filepath.Rel(src, dst)
+	if err != nil {
+		return nil
+	}
+	if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("cannot copy or move %s into itself", src)
+	}
+	return nil
+}
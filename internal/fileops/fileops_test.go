@@ -0,0 +1,111 @@
+package fileops
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testTree creates a directory containing a file, a subdirectory with a file, and returns its path.
+func testTree(tt *testing.T) string {
+	dir := filepath.Join(tt.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		tt.Fatal(err)
+	}
+	for _, name := range []string{"file", filepath.Join("sub", "nested")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o640); err != nil {
+			tt.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCopyMove(t *testing.T) {
+	tests := map[string]struct {
+		op         func(src string, dst string) error
+		dst        func(src string) string
+		wantSrc    bool
+		wantErr    bool
+		wantExists bool
+	}{
+		"copy": {
+			op:      Copy,
+			dst:     func(src string) string { return src + "-copy" },
+			wantSrc: true,
+		},
+		"move": {
+			op:      Move,
+			dst:     func(src string) string { return src + "-moved" },
+			wantSrc: false,
+		},
+		"copy_existing": {
+			op:         Copy,
+			dst:        func(src string) string { return filepath.Join(filepath.Dir(src), "src") },
+			wantErr:    true,
+			wantExists: true,
+		},
+		"move_into_itself": {
+			op:      Move,
+			dst:     func(src string) string { return filepath.Join(src, "sub", "src") },
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			src := testTree(tt)
+			dst := test.dst(src)
+
+			err := test.op(src, dst)
+			if test.wantErr {
+				if err == nil {
+					tt.Fatal("expected error")
+				}
+				if errors.Is(err, ErrExists) != test.wantExists {
+					tt.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dst, "sub", "nested"))
+			if err != nil || string(content) != filepath.Join("sub", "nested") {
+				tt.Fatalf("nested file not copied: %v", err)
+			}
+			info, err := os.Stat(filepath.Join(dst, "file"))
+			if err != nil || info.Mode().Perm() != 0o640 {
+				tt.Fatalf("file permissions not preserved: %v", err)
+			}
+			if _, err := os.Stat(src); (err == nil) != test.wantSrc {
+				tt.Fatalf("expected source to exist: %t", test.wantSrc)
+			}
+		})
+	}
+}
+
+func TestValidName(t *testing.T) {
+	tests := map[string]struct {
+		name  string
+		valid bool
+	}{
+		"name":      {name: "file.txt", valid: true},
+		"hidden":    {name: ".hidden", valid: true},
+		"empty":     {name: "", valid: false},
+		"dot":       {name: ".", valid: false},
+		"dot_dot":   {name: "..", valid: false},
+		"separator": {name: "dir/file", valid: false},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			if err := ValidName(test.name); (err == nil) != test.valid {
+				tt.Fatalf("expected valid %t, got error %v", test.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package fileops
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errorNotSameDevice is the Windows ERROR_NOT_SAME_DEVICE system error code.
+const errorNotSameDevice = syscall.Errno(17)
+
+func isCrossDevice(err error) bool {
+	return errors.Is(err, errorNotSameDevice)
+}
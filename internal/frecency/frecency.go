@@ -0,0 +1,303 @@
+// Package frecency ranks directories by how frequently and how recently they were visited, in the
+// manner of z and zoxide. The database is a file with one directory per line, each a rank, the
+// Unix time of the last visit, and an absolute path separated by tabs.
+package frecency
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dkaslovsky/nav/internal/xdg"
+)
+
+const (
+	// maxRank is the total rank above which all ranks are aged, so that directories that are no
+	// longer visited eventually drop out of the database.
+	maxRank = 10000
+	// minRank is the rank below which aged directories are removed.
+	minRank = 1
+	// maxEntries is the number of directories kept, dropping the lowest scored beyond it.
+	maxEntries = 1000
+)
+
+// Entry is a visited directory.
+type Entry struct {
+	Path     string
+	Rank     float64
+	Accessed time.Time
+}
+
+// Score weights the rank of the entry by the time since its last visit at now.
+func (e Entry) Score(now time.Time) float64 {
+	switch age := now.Sub(e.Accessed); {
+	case age < time.Hour:
+		return e.Rank * 4
+	case age < 24*time.Hour:
+		return e.Rank * 2
+	case age < 7*24*time.Hour:
+		return e.Rank / 2
+	}
+	return e.Rank / 4
+}
+
+// File returns the path of the database, $XDG_DATA_HOME/nav/frecency.
+func File() (string, error) {
+	dir, err := xdg.DataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "nav", "frecency"), nil
+}
+
+// DB is the set of visited directories read from a file.
+type DB struct {
+	file    string
+	entries []Entry
+}
+
+// Load reads the database from file. A file that does not exist holds no directories, and lines
+// that cannot be parsed are skipped so that a damaged database never prevents recording visits.
+func Load(file string) (*DB, error) {
+	db := &DB{file: file}
+
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 3)
+		if len(fields) != 3 || !filepath.IsAbs(fields[2]) {
+			continue
+		}
+		rank, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil || math.IsNaN(rank) || math.IsInf(rank, 0) || rank <= 0 {
+			continue
+		}
+		accessed, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		db.entries = append(db.entries, Entry{Path: fields[2], Rank: rank, Accessed: time.Unix(accessed, 0)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Add records visits to paths at now, locking the database so that visits recorded concurrently by
+// other processes are not lost.
+func Add(file string, now time.Time, paths ...string) error {
+	return update(file, func(db *DB) {
+		for _, path := range paths {
+			db.add(path, now)
+		}
+		db.age(now)
+	})
+}
+
+// Remove removes path from the database.
+func Remove(file string, path string) error {
+	return update(file, func(db *DB) {
+		for i := range db.entries {
+			if db.entries[i].Path == path {
+				db.entries = append(db.entries[:i], db.entries[i+1:]...)
+				return
+			}
+		}
+	})
+}
+
+// update applies fn to the database while holding its lock and saves the result.
+func update(file string, fn func(*DB)) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	unlock, err := lock(file + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	db, err := Load(file)
+	if err != nil {
+		return err
+	}
+	fn(db)
+	return db.save()
+}
+
+func (db *DB) add(path string, now time.Time) {
+	if !filepath.IsAbs(path) || strings.ContainsAny(path, "\r\n") {
+		return
+	}
+	path = filepath.Clean(path)
+	for i := range db.entries {
+		if db.entries[i].Path == path {
+			db.entries[i].Rank++
+			db.entries[i].Accessed = now
+			return
+		}
+	}
+	db.entries = append(db.entries, Entry{Path: path, Rank: 1, Accessed: now})
+}
+
+// age scales all ranks down once their total exceeds maxRank, removing entries that fall below
+// minRank, and keeps at most maxEntries entries.
+func (db *DB) age(now time.Time) {
+	total := 0.0
+	for _, e := range db.entries {
+		total += e.Rank
+	}
+	if total > maxRank {
+		factor := 0.9 * maxRank / total
+		entries := db.entries[:0]
+		for _, e := range db.entries {
+			if e.Rank *= factor; e.Rank >= minRank {
+				entries = append(entries, e)
+			}
+		}
+		db.entries = entries
+	}
+
+	if len(db.entries) > maxEntries {
+		db.entries = db.Sorted(now)[:maxEntries]
+	}
+}
+
+// save writes the database to its file, replacing it atomically so that readers that do not take
+// the lock never see a partial database.
+func (db *DB) save() error {
+	dir := filepath.Dir(db.file)
+
+	var content strings.Builder
+	for _, e := range db.entries {
+		fmt.Fprintf(&content, "%s\t%d\t%s\n", strconv.FormatFloat(e.Rank, 'f', -1, 64), e.Accessed.Unix(), e.Path)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(db.file)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(content.String()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), db.file); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Sorted returns the entries with the highest score at now first.
+func (db *DB) Sorted(now time.Time) []Entry {
+	entries := append([]Entry{}, db.entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Score(now) > entries[j].Score(now)
+	})
+	return entries
+}
+
+// Match returns the entries matching all keywords, highest score at now first. Keywords match
+// case-insensitively unless they contain an uppercase letter, must appear in the path in order,
+// and the last keyword must match within the last path component.
+func (db *DB) Match(keywords []string, now time.Time) []Entry {
+	matches := []Entry{}
+	for _, e := range db.Sorted(now) {
+		if matchKeywords(e.Path, keywords) {
+			matches = append(matches, e)
+		}
+	}
+	return matches
+}
+
+func matchKeywords(path string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return true
+	}
+
+	if _, ok := indexKeyword(filepath.Base(path), keywords[len(keywords)-1]); !ok {
+		return false
+	}
+
+	rest := path
+	for _, keyword := range keywords {
+		end, ok := indexKeyword(rest, keyword)
+		if !ok {
+			return false
+		}
+		rest = rest[end:]
+	}
+	return true
+}
+
+// indexKeyword returns the offset in s just past the first match of keyword, which matches
+// case-insensitively unless it contains an uppercase letter. Offsets are into s itself, as case
+// folding can change the length in bytes of a match.
+func indexKeyword(s string, keyword string) (int, bool) {
+	if strings.ToLower(keyword) != keyword {
+		i := strings.Index(s, keyword)
+		return i + len(keyword), i >= 0
+	}
+	for start := 0; start <= len(s); {
+		if end, ok := hasPrefixFold(s[start:], keyword); ok {
+			return start + end, true
+		}
+		if start == len(s) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[start:])
+		start += size
+	}
+	return 0, false
+}
+
+// hasPrefixFold reports whether s begins with prefix under Unicode case folding and returns the
+// length in bytes of the matching prefix of s.
+func hasPrefixFold(s string, prefix string) (int, bool) {
+	i := 0
+	for _, pr := range prefix {
+		if i >= len(s) {
+			return 0, false
+		}
+		sr, size := utf8.DecodeRuneInString(s[i:])
+		if !equalFoldRune(sr, pr) {
+			return 0, false
+		}
+		i += size
+	}
+	return i, true
+}
+
+func equalFoldRune(a rune, b rune) bool {
+	if a == b {
+		return true
+	}
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package frecency
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdd(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nav", "frecency")
+	now := time.Unix(1700000000, 0)
+
+	if err := Add(file, now, "/src", "/docs", "/src/", "relative"); err != nil {
+		t.Fatal(err)
+	}
+	db, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{{Path: "/src", Rank: 2, Accessed: now}, {Path: "/docs", Rank: 1, Accessed: now}}
+	if got := db.Sorted(now); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected entries %v, got %v", want, got)
+	}
+
+	if err := Remove(file, "/src"); err != nil {
+		t.Fatal(err)
+	}
+	if db, err = Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.Sorted(now); len(got) != 1 || got[0].Path != "/docs" {
+		t.Fatalf("expected only /docs, got %v", got)
+	}
+}
+
+func TestAddConcurrent(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "frecency")
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Add(file, now, "/src"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	db, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := db.Sorted(now); len(got) != 1 || got[0].Rank != 20 {
+		t.Fatalf("expected /src with rank 20, got %v", got)
+	}
+}
+
+func TestAge(t *testing.T) {
+	now := time.Now()
+	db := &DB{entries: []Entry{
+		{Path: "/a", Rank: maxRank, Accessed: now},
+		{Path: "/b", Rank: 1, Accessed: now},
+		{Path: "/c", Rank: 100, Accessed: now},
+	}}
+	db.age(now)
+
+	got := db.Sorted(now)
+	if len(got) != 2 || got[0].Path != "/a" || got[1].Path != "/c" {
+		t.Fatalf("expected /a and /c to remain, got %v", got)
+	}
+	total := got[0].Rank + got[1].Rank
+	if total > maxRank {
+		t.Fatalf("expected total rank at most %d, got %f", maxRank, total)
+	}
+
+	db = &DB{}
+	for i := 0; i < maxEntries+10; i++ {
+		db.add(filepath.Join("/", "dir", time.Duration(i).String()), now)
+	}
+	db.age(now)
+	if len(db.entries) != maxEntries {
+		t.Fatalf("expected %d entries, got %d", maxEntries, len(db.entries))
+	}
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Now()
+	db := &DB{entries: []Entry{
+		{Path: "/home/user/src/nav", Rank: 1, Accessed: now},
+		{Path: "/home/user/src/nav/internal", Rank: 5, Accessed: now.Add(-48 * time.Hour)},
+		{Path: "/home/user/Documents", Rank: 3, Accessed: now.Add(-30 * 24 * time.Hour)},
+		{Path: "/home/user/navigation/docs", Rank: 2, Accessed: now},
+		{Path: "/tmp/ȺȺȺȺx", Rank: 1, Accessed: now.Add(-365 * 24 * time.Hour)},
+	}}
+
+	tests := map[string]struct {
+		keywords []string
+		want     []string
+	}{
+		"all": {
+			keywords: nil,
+			want:     []string{"/home/user/navigation/docs", "/home/user/src/nav", "/home/user/src/nav/internal", "/home/user/Documents", "/tmp/ȺȺȺȺx"},
+		},
+		"last_keyword_in_last_component": {
+			keywords: []string{"nav"},
+			want:     []string{"/home/user/src/nav"},
+		},
+		"keywords_in_order": {
+			keywords: []string{"src", "int"},
+			want:     []string{"/home/user/src/nav/internal"},
+		},
+		"keywords_out_of_order": {
+			keywords: []string{"nav", "src"},
+			want:     []string{},
+		},
+		"smart_case": {
+			keywords: []string{"doc"},
+			want:     []string{"/home/user/navigation/docs", "/home/user/Documents"},
+		},
+		"uppercase_is_exact": {
+			keywords: []string{"Doc"},
+			want:     []string{"/home/user/Documents"},
+		},
+		"multibyte_fold_length": {
+			keywords: []string{"x"},
+			want:     []string{"/tmp/ȺȺȺȺx"},
+		},
+		"multibyte_fold": {
+			keywords: []string{"tmp", "ⱥⱥ", "ⱥx"},
+			want:     []string{"/tmp/ȺȺȺȺx"},
+		},
+		"multibyte_fold_in_order": {
+			keywords: []string{"ⱥⱥⱥ", "ⱥⱥ"},
+			want:     []string{},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got := []string{}
+			for _, e := range db.Match(test.keywords, now) {
+				got = append(got, e.Path)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected paths %v, got %v", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package frecency
+
+import (
+	"os"
+	"syscall"
+)
+
+// lock takes an exclusive lock on file, blocking until it is available, and returns a function
+// releasing it.
+func lock(file string) (func(), error) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
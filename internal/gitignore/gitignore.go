@@ -0,0 +1,342 @@
+// Package gitignore matches paths against the ignore rules of a git repository: the .gitignore
+// files of the repository's directories, .git/info/exclude, and the user's global excludes file,
+// following the pattern format described in gitignore(5).
+package gitignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dkaslovsky/nav/internal/xdg"
+)
+
+// GitDir is the name of the directory containing a repository's metadata, which is always ignored.
+const GitDir = ".git"
+
+const ignoreFile = ".gitignore"
+
+// pattern is a compiled line of an ignore file.
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool // Re-include paths excluded by a previous pattern.
+	dirOnly bool // Only match directories.
+	// base is the slash-separated directory, relative to the repository root, containing the
+	// ignore file. Patterns match paths relative to it.
+	base string
+}
+
+// Matcher reports whether paths in a repository are ignored. Ignore files of subdirectories are
+// read when a path within them is first matched. A Matcher is safe for concurrent use.
+type Matcher struct {
+	root string
+
+	mu sync.Mutex
+	// global holds the patterns of the global excludes file and .git/info/exclude, which have a
+	// lower precedence than the patterns of all .gitignore files.
+	global []*pattern
+	// dirs holds the patterns of the .gitignore file of each directory that has been read, keyed
+	// by the slash-separated path of the directory relative to the root.
+	dirs map[string][]*pattern
+}
+
+// FindRoot returns the root of the repository containing path, which is the closest ancestor
+// of path, or path itself, containing a .git entry.
+func FindRoot(path string) (string, bool) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Lstat(filepath.Join(path, GitDir)); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", false
+		}
+		path = parent
+	}
+}
+
+// New returns a Matcher for the repository at root. Missing or unreadable ignore files are
+// treated as empty.
+func New(root string) *Matcher {
+	m := &Matcher{
+		root: root,
+		dirs: map[string][]*pattern{},
+	}
+	if dir, err := xdg.ConfigHome(); err == nil {
+		m.global = append(m.global, readPatterns(filepath.Join(dir, "git", "ignore"), "")...)
+	}
+	m.global = append(m.global, readPatterns(filepath.Join(root, GitDir, "info", "exclude"), "")...)
+	return m
+}
+
+// Match reports whether the entry at path is ignored by the patterns that apply to it, without
+// considering whether one of its parent directories is ignored. It is intended for walking a
+// tree, where the entries of an ignored directory are known to be ignored. Paths outside of the
+// repository are not ignored. A nil Matcher ignores nothing.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	if filepath.Base(path) == GitDir {
+		return true
+	}
+	rel, ok := m.rel(path)
+	if !ok {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Patterns of deeper ignore files take precedence, as do later patterns within a file.
+	dir := parentDir(rel)
+	for {
+		if matched, ignored := matchPatterns(m.patterns(dir), rel, isDir); matched {
+			return ignored
+		}
+		if dir == "" {
+			break
+		}
+		dir = parentDir(dir)
+	}
+	_, ignored := matchPatterns(m.global, rel, isDir)
+	return ignored
+}
+
+// Ignored reports whether the entry at path is ignored, either by a pattern or because one of its
+// parent directories is ignored, in which case it cannot be re-included by a negated pattern.
+func (m *Matcher) Ignored(path string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	rel, ok := m.rel(path)
+	if !ok || rel == "" {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	for i := range parts[:len(parts)-1] {
+		dir := filepath.Join(m.root, filepath.FromSlash(strings.Join(parts[:i+1], "/")))
+		if m.Match(dir, true) {
+			return true
+		}
+	}
+	return m.Match(path, isDir)
+}
+
+// rel returns path relative to the root with slash separators.
+func (m *Matcher) rel(path string) (string, bool) {
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// patterns returns the patterns of the .gitignore file in dir, reading it on first use. The
+// caller must hold m.mu.
+func (m *Matcher) patterns(dir string) []*pattern {
+	if p, ok := m.dirs[dir]; ok {
+		return p
+	}
+	p := readPatterns(filepath.Join(m.root, filepath.FromSlash(dir), ignoreFile), dir)
+	m.dirs[dir] = p
+	return p
+}
+
+// matchPatterns evaluates patterns in reverse order and returns whether one matched and, if so,
+// whether it ignores the path.
+func matchPatterns(patterns []*pattern, rel string, isDir bool) (matched bool, ignored bool) {
+	for i := len(patterns) - 1; i >= 0; i-- {
+		p := patterns[i]
+		if p.dirOnly && !isDir {
+			continue
+		}
+		path := rel
+		if p.base != "" {
+			var ok bool
+			if path, ok = strings.CutPrefix(rel, p.base+"/"); !ok {
+				continue
+			}
+		}
+		if p.re.MatchString(path) {
+			return true, !p.negate
+		}
+	}
+	return false, false
+}
+
+func parentDir(rel string) string {
+	if i := strings.LastIndexByte(rel, '/'); i >= 0 {
+		return rel[:i]
+	}
+	return ""
+}
+
+// readPatterns parses the ignore file at path, returning nil if it cannot be read.
+func readPatterns(path string, base string) []*pattern {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var patterns []*pattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if p, ok := parsePattern(scanner.Text(), base); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// parsePattern compiles a line of an ignore file, returning false for blank lines, comments and
+// invalid patterns.
+func parsePattern(line string, base string) (*pattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimTrailingSpaces(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, false
+	}
+
+	p := &pattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") && !strings.HasSuffix(line, "\\/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil, false
+	}
+
+	// A pattern containing a separator is matched relative to the directory of the ignore file,
+	// otherwise it matches a name at any level below it.
+	prefix := "^(?:.*/)?"
+	if strings.Contains(line, "/") {
+		prefix = "^"
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	re, err := regexp.Compile(prefix + translate(line) + "$")
+	if err != nil {
+		return nil, false
+	}
+	p.re = re
+	return p, true
+}
+
+// trimTrailingSpaces removes trailing spaces that are not escaped with a backslash.
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") {
+		trimmed := line[:len(line)-1]
+		if strings.HasSuffix(trimmed, "\\") && !strings.HasSuffix(trimmed, "\\\\") {
+			return line
+		}
+		line = trimmed
+	}
+	return line
+}
+
+// translate converts a glob pattern to a regular expression.
+func translate(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if !strings.HasPrefix(glob[i:], "**") {
+				b.WriteString("[^/]*")
+				continue
+			}
+			start, end := i == 0 || glob[i-1] == '/', i+2 == len(glob) || glob[i+2] == '/'
+			i++
+			switch {
+			case !start || !end:
+				// Consecutive asterisks not forming a path component are regular asterisks.
+				b.WriteString("[^/]*")
+			case i+1 == len(glob):
+				// A trailing "**" matches everything inside, and a lone "**" matches everything.
+				b.WriteString(".*")
+			default:
+				// A leading or inner "**/" matches zero or more directories.
+				b.WriteString("(?:.*/)?")
+				i++
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			class, n, ok := translateClass(glob[i:])
+			if !ok {
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(class)
+			i += n - 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				c = glob[i]
+			}
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// translateClass converts the bracket expression at the start of glob to a regular expression and
+// returns the number of bytes consumed, or false if the expression is not terminated.
+func translateClass(glob string) (string, int, bool) {
+	var b strings.Builder
+	b.WriteString("[")
+	i := 1
+	if i < len(glob) && (glob[i] == '!' || glob[i] == '^') {
+		b.WriteString("^")
+		i++
+	}
+	// A closing bracket in the first position is a literal.
+	if i < len(glob) && glob[i] == ']' {
+		b.WriteString(`\]`)
+		i++
+	}
+	for ; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case ']':
+			b.WriteString("]")
+			return b.String(), i + 1, true
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			b.WriteString(classLiteral(glob[i]))
+		case '[', '^':
+			b.WriteString(`\` + string(c))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// classLiteral escapes a character for use in a regular expression character class.
+func classLiteral(c byte) string {
+	if strings.IndexByte(`\]^-[`, c) >= 0 {
+		return `\` + string(c)
+	}
+	return string(c)
+}
@@ -0,0 +1,146 @@
+package gitignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		base    string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		"name_at_root":               {pattern: "foo", path: "foo", want: true},
+		"name_nested":                {pattern: "foo", path: "a/b/foo", want: true},
+		"name_prefix":                {pattern: "foo", path: "foobar", want: false},
+		"wildcard":                   {pattern: "*.log", path: "a/debug.log", want: true},
+		"wildcard_no_separator":      {pattern: "a*b", path: "a/b", want: false},
+		"question_mark":              {pattern: "?.txt", path: "a.txt", want: true},
+		"question_mark_separator":    {pattern: "a?b", path: "a/b", want: false},
+		"class":                      {pattern: "[abc].go", path: "b.go", want: true},
+		"class_negated":              {pattern: "[!abc].go", path: "b.go", want: false},
+		"class_range":                {pattern: "file[0-9]", path: "file7", want: true},
+		"class_unterminated":         {pattern: "[abc", path: "[abc", want: true},
+		"anchored_leading_slash":     {pattern: "/foo", path: "a/foo", want: false},
+		"anchored_leading_slash_top": {pattern: "/foo", path: "foo", want: true},
+		"anchored_inner_slash":       {pattern: "a/foo", path: "b/a/foo", want: false},
+		"anchored_inner_slash_top":   {pattern: "a/foo", path: "a/foo", want: true},
+		"dir_only_dir":               {pattern: "build/", path: "x/build", isDir: true, want: true},
+		"dir_only_file":              {pattern: "build/", path: "x/build", want: false},
+		"leading_double_star":        {pattern: "**/foo", path: "a/b/foo", want: true},
+		"leading_double_star_top":    {pattern: "**/foo", path: "foo", want: true},
+		"trailing_double_star":       {pattern: "foo/**", path: "foo/a/b", want: true},
+		"trailing_double_star_self":  {pattern: "foo/**", path: "foo", isDir: true, want: false},
+		"inner_double_star":          {pattern: "a/**/b", path: "a/x/y/b", want: true},
+		"inner_double_star_zero":     {pattern: "a/**/b", path: "a/b", want: true},
+		"double_star_in_name":        {pattern: "a**b", path: "a/b", want: false},
+		"escaped_wildcard":           {pattern: `\*.go`, path: "x.go", want: false},
+		"escaped_wildcard_literal":   {pattern: `\*.go`, path: "*.go", want: true},
+		"escaped_hash":               {pattern: `\#notes`, path: "#notes", want: true},
+		"escaped_trailing_space":     {pattern: `foo\ `, path: "foo ", want: true},
+		"trailing_space_trimmed":     {pattern: "foo  ", path: "foo", want: true},
+		"base_relative":              {pattern: "foo", base: "sub", path: "sub/x/foo", want: true},
+		"base_anchored":              {pattern: "/foo", base: "sub", path: "sub/foo", want: true},
+		"base_outside":               {pattern: "foo", base: "sub", path: "other/foo", want: false},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			p, ok := parsePattern(test.pattern, test.base)
+			if !ok {
+				tt.Fatalf("failed to parse pattern %q", test.pattern)
+			}
+			matched, ignored := matchPatterns([]*pattern{p}, test.path, test.isDir)
+			if got := matched && ignored; got != test.want {
+				tt.Fatalf("expected match %t for %q against %q, got %t", test.want, test.pattern, test.path, got)
+			}
+		})
+	}
+}
+
+func TestParsePatternSkipped(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "!", "/"} {
+		if _, ok := parsePattern(line, ""); ok {
+			t.Fatalf("expected line %q to be skipped", line)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+
+	files := map[string]string{
+		filepath.Join("config", "git", "ignore"): "*.swp\n",
+		filepath.Join(".git", "info", "exclude"): "local/\n",
+		".gitignore":                             "*.log\n!keep.log\nnode_modules/\n/build\n",
+		filepath.Join("sub", ".gitignore"):       "!*.log\ngenerated\n",
+		filepath.Join("sub", "deep", "x"):        "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		"git_dir":                 {path: ".git", isDir: true, want: true},
+		"global_excludes":         {path: "a.swp", want: true},
+		"info_exclude":            {path: "local", isDir: true, want: true},
+		"root_pattern":            {path: "debug.log", want: true},
+		"root_negated":            {path: "keep.log", want: false},
+		"dir_only":                {path: "a/node_modules", isDir: true, want: true},
+		"anchored":                {path: "build", isDir: true, want: true},
+		"anchored_nested":         {path: "a/build", isDir: true, want: false},
+		"nested_negation":         {path: "sub/debug.log", want: false},
+		"nested_pattern":          {path: "sub/deep/generated", want: true},
+		"nested_pattern_outside":  {path: "generated", want: false},
+		"inside_ignored_dir":      {path: "a/node_modules/pkg/index.js", want: true},
+		"negation_in_ignored_dir": {path: "build/keep.log", want: true},
+		"not_ignored":             {path: "main.go", want: false},
+		"outside_root":            {path: "../other", want: false},
+	}
+
+	m := New(root)
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			path := filepath.Join(root, filepath.FromSlash(test.path))
+			if got := m.Ignored(path, test.isDir); got != test.want {
+				tt.Fatalf("expected ignored %t for %q, got %t", test.want, test.path, got)
+			}
+		})
+	}
+}
+
+func TestFindRoot(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(filepath.Join(root, GitDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := FindRoot(sub)
+	if !ok {
+		t.Fatal("expected to find repository root")
+	}
+	if got != root {
+		t.Fatalf("expected root %q, got %q", root, got)
+	}
+}
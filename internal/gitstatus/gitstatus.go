@@ -0,0 +1,206 @@
+// Package gitstatus reads the status of the entries of a git repository from the output of
+// `git status --porcelain=v2`.
+package gitstatus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// State is a set of flags describing the git state of an entry.
+type State uint8
+
+const (
+	Modified   State = 1 << iota // Changed in the working tree but not staged.
+	Staged                       // Changed in the index.
+	Untracked                    // Not tracked.
+	Ignored                      // Ignored by the repository's ignore rules.
+	Conflicted                   // Unmerged.
+	Changes                      // A directory containing entries with any of the other states.
+)
+
+// Has returns true if all flags of t are set in s.
+func (s State) Has(t State) bool {
+	return s&t == t
+}
+
+// timeout limits the time spent running git for a large repository.
+const timeout = 10 * time.Second
+
+// Status is the status of the entries of a repository.
+type Status struct {
+	Root   string
+	Branch string // Name of the checked out branch, or a commit abbreviation when detached.
+
+	// entries holds the state of each changed entry, keyed by its slash-separated path relative to
+	// the root. Untracked and ignored directories are listed without their contents.
+	entries map[string]State
+	// dirs holds the combined state of the changed entries within each directory.
+	dirs map[string]State
+}
+
+// Load runs git to read the status of the repository at root.
+func Load(root string) (*Status, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "-C", root,
+		"status", "--porcelain=v2", "-z", "--branch", "--ignored=matching")
+	// Avoid taking the index lock, which could interfere with git commands run by the user.
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git status: %s", msg)
+		}
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+	return Parse(root, out)
+}
+
+// Parse reads the NUL-separated output of `git status --porcelain=v2 -z --branch` for the
+// repository at root.
+func Parse(root string, out []byte) (*Status, error) {
+	s := &Status{
+		Root:    root,
+		entries: map[string]State{},
+		dirs:    map[string]State{},
+	}
+
+	records := strings.Split(string(out), "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if record == "" {
+			continue
+		}
+
+		var (
+			state State
+			path  string
+		)
+		switch record[0] {
+		case '#':
+			s.parseHeader(record)
+			continue
+		case '1':
+			fields := strings.SplitN(record, " ", 9)
+			if len(fields) != 9 {
+				return nil, fmt.Errorf("malformed status record %q", record)
+			}
+			state, path = parseXY(fields[1]), fields[8]
+		case '2':
+			fields := strings.SplitN(record, " ", 10)
+			if len(fields) != 10 {
+				return nil, fmt.Errorf("malformed status record %q", record)
+			}
+			state, path = parseXY(fields[1]), fields[9]
+			// The original path of a rename or copy follows as a separate record.
+			i++
+		case 'u':
+			fields := strings.SplitN(record, " ", 11)
+			if len(fields) != 11 {
+				return nil, fmt.Errorf("malformed status record %q", record)
+			}
+			state, path = Conflicted, fields[10]
+		case '?':
+			state, path = Untracked, strings.TrimPrefix(record, "? ")
+		case '!':
+			state, path = Ignored, strings.TrimPrefix(record, "! ")
+		default:
+			return nil, fmt.Errorf("unknown status record %q", record)
+		}
+		s.add(strings.TrimSuffix(path, "/"), state)
+	}
+	return s, nil
+}
+
+func (s *Status) parseHeader(record string) {
+	fields := strings.Fields(record)
+	if len(fields) < 3 {
+		return
+	}
+	switch fields[1] {
+	case "branch.head":
+		if fields[2] != "(detached)" {
+			s.Branch = fields[2]
+		}
+	case "branch.oid":
+		if s.Branch == "" && len(fields[2]) >= 7 {
+			s.Branch = fields[2][:7]
+		}
+	}
+}
+
+// parseXY converts the two-letter staged and unstaged status of a changed entry.
+func parseXY(xy string) State {
+	var state State
+	if len(xy) != 2 {
+		return state
+	}
+	if xy[0] != '.' {
+		state |= Staged
+	}
+	if xy[1] != '.' {
+		state |= Modified
+	}
+	return state
+}
+
+// add records the state of an entry and marks its parent directories as containing changes.
+// Ignored entries do not count as changes.
+func (s *Status) add(path string, state State) {
+	s.entries[path] |= state
+	if state == Ignored {
+		return
+	}
+	for dir := path; ; {
+		i := strings.LastIndexByte(dir, '/')
+		if i < 0 {
+			break
+		}
+		dir = dir[:i]
+		s.dirs[dir] |= state | Changes
+	}
+}
+
+// Lookup returns the state of the entry at path. The contents of untracked and ignored directories
+// share the state of the directory. A directory containing changes has the Changes flag set along
+// with the states of the changed entries.
+func (s *Status) Lookup(path string, isDir bool) State {
+	if s == nil {
+		return 0
+	}
+	rel, err := filepath.Rel(s.Root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return 0
+	}
+	rel = filepath.ToSlash(rel)
+
+	state := s.entries[rel]
+	if isDir {
+		state |= s.dirs[rel]
+	}
+	if state != 0 {
+		return state
+	}
+
+	for dir := rel; ; {
+		i := strings.LastIndexByte(dir, '/')
+		if i < 0 {
+			return 0
+		}
+		dir = dir[:i]
+		if st := s.entries[dir]; st == Untracked || st == Ignored {
+			return st
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package gitstatus
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	out := strings.Join([]string{
+		"# branch.oid 0123456789abcdef0123456789abcdef01234567",
+		"# branch.head main",
+		"1 .M N... 100644 100644 100644 0123456 0123456 src/main.go",
+		"1 A. N... 000000 100644 100644 0000000 0123456 src/new file.go",
+		"1 MM N... 100644 100644 100644 0123456 0123456 README.md",
+		"2 R. N... 100644 100644 100644 0123456 0123456 R100 docs/b.md",
+		"docs/a.md",
+		"u UU N... 100644 100644 100644 100644 0123456 0123456 0123456 lib/conflict.go",
+		"? scratch/",
+		"! node_modules/",
+		"",
+	}, "\x00")
+
+	s, err := Parse(root, []byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Branch != "main" {
+		t.Fatalf("expected branch %q, got %q", "main", s.Branch)
+	}
+
+	tests := map[string]struct {
+		path  string
+		isDir bool
+		want  State
+	}{
+		"modified":          {path: "src/main.go", want: Modified},
+		"staged":            {path: "src/new file.go", want: Staged},
+		"staged_modified":   {path: "README.md", want: Staged | Modified},
+		"renamed":           {path: "docs/b.md", want: Staged},
+		"rename_source":     {path: "docs/a.md", want: 0},
+		"conflicted":        {path: "lib/conflict.go", want: Conflicted},
+		"untracked_dir":     {path: "scratch", isDir: true, want: Untracked},
+		"untracked_nested":  {path: "scratch/a/b.txt", want: Untracked},
+		"ignored_dir":       {path: "node_modules", isDir: true, want: Ignored},
+		"ignored_nested":    {path: "node_modules/pkg", isDir: true, want: Ignored},
+		"dir_changes":       {path: "src", isDir: true, want: Modified | Staged | Changes},
+		"dir_conflicts":     {path: "lib", isDir: true, want: Conflicted | Changes},
+		"unchanged":         {path: "go.mod", want: 0},
+		"unchanged_dir":     {path: "internal", isDir: true, want: 0},
+		"root":              {path: "", isDir: true, want: 0},
+		"outside_root":      {path: "../other", want: 0},
+		"file_named_as_dir": {path: "src", want: 0},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got := s.Lookup(filepath.Join(root, filepath.FromSlash(test.path)), test.isDir)
+			if got != test.want {
+				tt.Fatalf("expected state %06b for %q, got %06b", test.want, test.path, got)
+			}
+		})
+	}
+}
+
+func TestParseDetached(t *testing.T) {
+	out := "# branch.oid 0123456789abcdef0123456789abcdef01234567\x00# branch.head (detached)\x00"
+	s, err := Parse("/repo", []byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Branch != "0123456" {
+		t.Fatalf("expected branch %q, got %q", "0123456", s.Branch)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	if _, err := Parse("/repo", []byte("1 .M N...\x00")); err == nil {
+		t.Fatal("expected error for malformed record")
+	}
+}
@@ -0,0 +1,240 @@
+// Package indexcache stores the directory listings of a tree on disk so that the tree can be
+// indexed without reading every directory again. Each tree root has its own file in
+// $XDG_CACHE_HOME/nav/index, and the least recently used files are evicted to keep the cache within
+// a size limit.
+package indexcache
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dkaslovsky/nav/internal/xdg"
+)
+
+// version is the version of the file format. Files of other versions hold no directories, so
+// changing the format rebuilds the cache.
+const version = 2
+
+// ErrTooLarge is returned when saving an index larger than the limit on the size of the cache.
+var ErrTooLarge = errors.New("index is larger than the cache size limit")
+
+// Entry is an entry of a directory.
+type Entry struct {
+	Name    string
+	Mode    fs.FileMode
+	Size    int64
+	ModTime int64 // Unix time in nanoseconds.
+}
+
+// NewEntry returns the entry described by info.
+func NewEntry(info fs.FileInfo) Entry {
+	return Entry{
+		Name:    info.Name(),
+		Mode:    info.Mode(),
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+	}
+}
+
+// DirEntry returns the entry as read from its directory.
+func (e Entry) DirEntry() fs.DirEntry {
+	return fileInfo{e}
+}
+
+// fileInfo implements fs.DirEntry and fs.FileInfo for an entry.
+type fileInfo struct {
+	e Entry
+}
+
+func (f fileInfo) Name() string               { return f.e.Name }
+func (f fileInfo) Size() int64                { return f.e.Size }
+func (f fileInfo) Mode() fs.FileMode          { return f.e.Mode }
+func (f fileInfo) ModTime() time.Time         { return time.Unix(0, f.e.ModTime) }
+func (f fileInfo) IsDir() bool                { return f.e.Mode.IsDir() }
+func (f fileInfo) Sys() any                   { return nil }
+func (f fileInfo) Type() fs.FileMode          { return f.e.Mode.Type() }
+func (f fileInfo) Info() (fs.FileInfo, error) { return f, nil }
+
+// Dir is the listing of a directory.
+type Dir struct {
+	ModTime int64 // Modification time of the directory when it was listed, in Unix nanoseconds.
+	Entries []Entry
+}
+
+// Index is the listing of the directories of a tree by absolute path.
+type Index struct {
+	Root string
+	Dirs map[string]Dir
+}
+
+// header precedes the directories in a file so that a file of another version or root is rejected
+// without decoding its directories.
+type header struct {
+	Version int
+	Root    string
+	Dirs    int // Number of directories that follow.
+}
+
+// record is a directory in a file. Each directory is encoded on its own so that loading can be
+// canceled between directories.
+type record struct {
+	Path string
+	Dir  Dir
+}
+
+// File returns the path of the file caching the index of root.
+func File(root string) (string, error) {
+	dir, err := xdg.CacheHome()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(dir, "nav", "index", hex.EncodeToString(sum[:16])), nil
+}
+
+// Load reads the index of root from file. A file that does not exist, or that holds the index of
+// another root or format version, holds no directories. Loading a file marks it as recently used.
+// Loading stops with the error of ctx once it is done.
+func Load(ctx context.Context, file string, root string) (*Index, error) {
+	index := &Index{Root: root, Dirs: map[string]Dir{}}
+
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(bufio.NewReader(f))
+	var h header
+	if err := dec.Decode(&h); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if h.Version != version || h.Root != root {
+		return index, nil
+	}
+	for i := 0; i < h.Dirs; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		index.Dirs[r.Path] = r.Dir
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(file, now, now)
+	return index, nil
+}
+
+// Save writes the index to file, creating its directory if needed, and then evicts the least
+// recently used files in the directory until their total size is within limit. An index larger
+// than limit is not saved. The file is replaced atomically so that a concurrent reader never sees a
+// partial index.
+func Save(file string, index *Index, limit int64) error {
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	w := &limitWriter{w: tmp, n: limit}
+	buf := bufio.NewWriter(w)
+	enc := gob.NewEncoder(buf)
+	err = enc.Encode(header{Version: version, Root: index.Root, Dirs: len(index.Dirs)})
+	if err == nil {
+		for path, d := range index.Dirs {
+			if err = enc.Encode(record{Path: path, Dir: d}); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return evict(dir, limit, file)
+}
+
+// limitWriter writes to w until more than n bytes are written.
+type limitWriter struct {
+	w *os.File
+	n int64
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.n {
+		return 0, ErrTooLarge
+	}
+	n, err := l.w.Write(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// evict removes the least recently used files in dir, other than keep, until the total size of the
+// files is within limit.
+func evict(dir string, limit int64, keep string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		path string
+		info fs.FileInfo
+	}
+	files := make([]file, 0, len(entries))
+	for _, ent := range entries {
+		info, err := ent.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, file{path: filepath.Join(dir, ent.Name()), info: info})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].path == keep || files[j].path == keep {
+			return files[i].path == keep
+		}
+		return files[i].info.ModTime().After(files[j].info.ModTime())
+	})
+
+	var total int64
+	for _, f := range files {
+		total += f.info.Size()
+		if total > limit {
+			if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package indexcache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveLoad(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", root)
+
+	file, err := File("/src/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(file) != filepath.Join(root, "nav", "index") {
+		t.Fatalf("expected file in %s, got %s", filepath.Join(root, "nav", "index"), file)
+	}
+	if other, _ := File("/src/other"); other == file {
+		t.Fatalf("expected roots to have different files, got %s", file)
+	}
+
+	// A missing file holds no directories.
+	index, err := Load(context.Background(), file, "/src/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Dirs) != 0 {
+		t.Fatalf("expected no directories, got %v", index.Dirs)
+	}
+
+	index.Dirs["/src/repo/cmd"] = Dir{
+		ModTime: 5,
+		Entries: []Entry{{Name: "main.go", Mode: 0o644, Size: 10, ModTime: 3}, {Name: "sub", Mode: os.ModeDir | 0o755}},
+	}
+	if err := Save(file, index, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(context.Background(), file, "/src/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, index) {
+		t.Fatalf("expected index %v, got %v", index, loaded)
+	}
+
+	// Loading stops once canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Load(ctx, file, "/src/repo"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the load to be canceled, got %v", err)
+	}
+
+	// The file of another root holds no directories.
+	other, err := Load(context.Background(), file, "/src/other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Dirs) != 0 {
+		t.Fatalf("expected no directories, got %v", other.Dirs)
+	}
+
+	ent := loaded.Dirs["/src/repo/cmd"].Entries[1].DirEntry()
+	if info, _ := ent.Info(); ent.Name() != "sub" || !ent.IsDir() || !info.Mode().IsDir() {
+		t.Fatalf("expected directory entry sub, got %v", info)
+	}
+}
+
+func TestSaveLimit(t *testing.T) {
+	dir := t.TempDir()
+	index := &Index{Root: "/a", Dirs: map[string]Dir{"/a": {Entries: []Entry{{Name: "file"}}}}}
+
+	if err := Save(filepath.Join(dir, "a"), index, 16); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected error %v, got %v", ErrTooLarge, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("expected no files, got %v", entries)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	dir := t.TempDir()
+	index := &Index{Root: "/a", Dirs: map[string]Dir{"/a": {Entries: []Entry{{Name: "file"}}}}}
+
+	// Save three indexes used in turn, each a minute after the previous one.
+	now := time.Now()
+	var size int64
+	for i, name := range []string{"old", "mid", "new"} {
+		file := filepath.Join(dir, name)
+		if err := Save(file, index, 1<<20); err != nil {
+			t.Fatal(err)
+		}
+		used := now.Add(time.Duration(i-3) * time.Minute)
+		if err := os.Chtimes(file, used, used); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size = info.Size()
+	}
+
+	// Saving an index that was used least recently keeps it and evicts the others beyond the limit.
+	if err := Save(filepath.Join(dir, "old"), index, 2*size); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, ent := range entries {
+		got = append(got, ent.Name())
+	}
+	if want := []string{"new", "old"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected files %v, got %v", want, got)
+	}
+}
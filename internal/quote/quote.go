@@ -0,0 +1,221 @@
+// Package quote quotes strings, such as file paths, so that a shell evaluates them to the original
+// string. Quoting is applied only when a string contains characters that are special to the shell.
+package quote
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Style selects the shell whose quoting rules are followed.
+type Style int
+
+const (
+	None       Style = iota // No quoting.
+	POSIX                   // POSIX sh single quotes.
+	Bash                    // Single quotes, or ANSI-C $'...' quotes for non-printable bytes.
+	Zsh                     // Single quotes, or ANSI-C $'...' quotes for non-printable bytes.
+	Fish                    // Single quotes with unquoted \xHH escapes for non-printable bytes.
+	PowerShell              // Single quotes, which do not expand variables or escape sequences.
+)
+
+var styleNames = []string{
+	None:       "none",
+	POSIX:      "posix",
+	Bash:       "bash",
+	Zsh:        "zsh",
+	Fish:       "fish",
+	PowerShell: "powershell",
+}
+
+func (s Style) String() string {
+	if int(s) < len(styleNames) {
+		return styleNames[s]
+	}
+	return fmt.Sprintf("Style(%d)", int(s))
+}
+
+// ParseStyle returns the style with the given name.
+func ParseStyle(name string) (Style, error) {
+	for s, n := range styleNames {
+		if n == name {
+			return Style(s), nil
+		}
+	}
+	return None, fmt.Errorf("invalid quoting style %q, expected one of %s", name, strings.Join(styleNames, ", "))
+}
+
+// Quote returns s quoted for the shell of the given style. Strings consisting only of characters
+// that are never special to the shell are returned unchanged.
+func Quote(s string, style Style) string {
+	switch style {
+	case POSIX:
+		if isSafe(s, safePOSIX) {
+			return s
+		}
+		return singleQuotePOSIX(s)
+	case Bash, Zsh:
+		if isSafe(s, safePOSIX) {
+			return s
+		}
+		if isPrintable(s) {
+			return singleQuotePOSIX(s)
+		}
+		return ansiCQuote(s)
+	case Fish:
+		if isSafe(s, safePOSIX) {
+			return s
+		}
+		return quoteFish(s)
+	case PowerShell:
+		if isSafe(s, safePowerShell) && !strings.HasPrefix(s, "-") {
+			return s
+		}
+		return quotePowerShell(s)
+	}
+	return s
+}
+
+const (
+	// safePOSIX are the punctuation characters that are not special to POSIX shells, bash, zsh,
+	// or fish in any position.
+	safePOSIX = "_-+=.,/:@"
+	// safePowerShell are the punctuation characters that are not special to PowerShell in any
+	// position other than a leading "-", which starts a parameter name. The "," is not safe as it
+	// separates the elements of an array.
+	safePowerShell = `_-./:\`
+)
+
+func isSafe(s string, punct string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(punct, c) >= 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPrintable returns true if s is valid UTF-8 without control characters.
+func isPrintable(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) && r != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// singleQuotePOSIX quotes s in single quotes, within which every byte is literal, including
+// newlines and invalid UTF-8. A single quote is written by closing the quotes, escaping it, and
+// reopening the quotes.
+func singleQuotePOSIX(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ansiCQuote quotes s in the $'...' form supported by bash and zsh, escaping backslashes, single
+// quotes, and all bytes that are not printable UTF-8.
+func ansiCQuote(s string) string {
+	var b strings.Builder
+	b.WriteString("$'")
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			// Invalid UTF-8: escape the byte. Two hex digits are always written so that a
+			// following hex digit is not consumed by the escape.
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\\' || r == '\'':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < utf8.RuneSelf && !unicode.IsPrint(r) && r != ' ':
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case !unicode.IsPrint(r) && r != ' ':
+			// Non-printable runes, such as bidirectional controls, are written byte by byte.
+			for _, c := range []byte(s[i : i+size]) {
+				fmt.Fprintf(&b, `\x%02x`, c)
+			}
+		default:
+			b.WriteRune(r)
+		}
+		i += size
+	}
+	b.WriteString("'")
+	return b.String()
+}
+
+// quoteFish quotes s in fish single quotes, within which only backslashes and single quotes are
+// escaped. Fish does not interpret escape sequences in quotes, so bytes that are not printable
+// UTF-8 are written as unquoted \xHH escapes between quoted segments, which fish concatenates.
+func quoteFish(s string) string {
+	var b strings.Builder
+	quoted := false
+	setQuoted := func(q bool) {
+		if q != quoted {
+			b.WriteByte('\'')
+			quoted = q
+		}
+	}
+
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size <= 1) || (!unicode.IsPrint(r) && r != ' ') {
+			setQuoted(false)
+			for _, c := range []byte(s[i : i+size]) {
+				fmt.Fprintf(&b, `\x%02x`, c)
+			}
+			i += size
+			continue
+		}
+
+		setQuoted(true)
+		if r == '\\' || r == '\'' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+		i += size
+	}
+	if b.Len() == 0 {
+		return "''"
+	}
+	setQuoted(false)
+	return b.String()
+}
+
+// quotePowerShell quotes s in PowerShell single quotes, within which a single quote, including the
+// typographic single quotes that PowerShell also accepts as delimiters, is escaped by doubling it.
+// PowerShell strings are UTF-16, so invalid UTF-8 cannot be represented and is written unchanged.
+func quotePowerShell(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		if isPowerShellSingleQuote(r) {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+func isPowerShellSingleQuote(r rune) bool {
+	switch r {
+	case '\'', '‘', '’', '‚', '‛':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+package quote
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestParseStyle(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		want    Style
+		wantErr bool
+	}{
+		"none":       {name: "none", want: None},
+		"posix":      {name: "posix", want: POSIX},
+		"bash":       {name: "bash", want: Bash},
+		"zsh":        {name: "zsh", want: Zsh},
+		"fish":       {name: "fish", want: Fish},
+		"powershell": {name: "powershell", want: PowerShell},
+		"unknown":    {name: "csh", wantErr: true},
+		"case":       {name: "POSIX", wantErr: true},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got, err := ParseStyle(test.name)
+			if test.wantErr {
+				if err == nil {
+					tt.Fatalf("expected error for %q", test.name)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if got != test.want {
+				tt.Fatalf("expected style %v, got %v", test.want, got)
+			}
+			if got.String() != test.name {
+				tt.Fatalf("expected name %q, got %q", test.name, got.String())
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := map[string]struct {
+		s    string
+		want map[Style]string
+	}{
+		"safe": {
+			s: "/home/user/file-1.2_a+b=c,d:e@f",
+			want: map[Style]string{
+				None:       "/home/user/file-1.2_a+b=c,d:e@f",
+				POSIX:      "/home/user/file-1.2_a+b=c,d:e@f",
+				Bash:       "/home/user/file-1.2_a+b=c,d:e@f",
+				Zsh:        "/home/user/file-1.2_a+b=c,d:e@f",
+				Fish:       "/home/user/file-1.2_a+b=c,d:e@f",
+				PowerShell: "'/home/user/file-1.2_a+b=c,d:e@f'",
+			},
+		},
+		"empty": {
+			s: "",
+			want: map[Style]string{
+				None:       "",
+				POSIX:      "''",
+				Bash:       "''",
+				Zsh:        "''",
+				Fish:       "''",
+				PowerShell: "''",
+			},
+		},
+		"space": {
+			s: "/tmp/a b",
+			want: map[Style]string{
+				None:       "/tmp/a b",
+				POSIX:      "'/tmp/a b'",
+				Bash:       "'/tmp/a b'",
+				Zsh:        "'/tmp/a b'",
+				Fish:       "'/tmp/a b'",
+				PowerShell: "'/tmp/a b'",
+			},
+		},
+		"single_quote": {
+			s: "it's",
+			want: map[Style]string{
+				POSIX:      `'it'\''s'`,
+				Bash:       `'it'\''s'`,
+				Zsh:        `'it'\''s'`,
+				Fish:       `'it\'s'`,
+				PowerShell: `'it''s'`,
+			},
+		},
+		"double_quote": {
+			s: `say "hi"`,
+			want: map[Style]string{
+				POSIX:      `'say "hi"'`,
+				Bash:       `'say "hi"'`,
+				Zsh:        `'say "hi"'`,
+				Fish:       `'say "hi"'`,
+				PowerShell: `'say "hi"'`,
+			},
+		},
+		"metacharacters": {
+			s: "$HOME`id`*?[a]{b,c}~!#;&|<>()",
+			want: map[Style]string{
+				POSIX:      "'$HOME`id`*?[a]{b,c}~!#;&|<>()'",
+				Bash:       "'$HOME`id`*?[a]{b,c}~!#;&|<>()'",
+				Zsh:        "'$HOME`id`*?[a]{b,c}~!#;&|<>()'",
+				Fish:       "'$HOME`id`*?[a]{b,c}~!#;&|<>()'",
+				PowerShell: "'$HOME`id`*?[a]{b,c}~!#;&|<>()'",
+			},
+		},
+		"backslash": {
+			s: `a\b`,
+			want: map[Style]string{
+				POSIX:      `'a\b'`,
+				Bash:       `'a\b'`,
+				Zsh:        `'a\b'`,
+				Fish:       `'a\\b'`,
+				PowerShell: `a\b`,
+			},
+		},
+		"newline": {
+			s: "a\nb",
+			want: map[Style]string{
+				POSIX:      "'a\nb'",
+				Bash:       `$'a\nb'`,
+				Zsh:        `$'a\nb'`,
+				Fish:       `'a'\x0a'b'`,
+				PowerShell: "'a\nb'",
+			},
+		},
+		"control_and_quote": {
+			s: "\x1b[31m'x",
+			want: map[Style]string{
+				POSIX:      "'\x1b[31m'\\''x'",
+				Bash:       `$'\x1b[31m\'x'`,
+				Zsh:        `$'\x1b[31m\'x'`,
+				Fish:       `\x1b'[31m\'x'`,
+				PowerShell: "'\x1b[31m''x'",
+			},
+		},
+		"escape_followed_by_hex_digit": {
+			s: "\x01f",
+			want: map[Style]string{
+				Bash: `$'\x01f'`,
+				Fish: `\x01'f'`,
+			},
+		},
+		"invalid_utf8": {
+			s: "a\xffb",
+			want: map[Style]string{
+				None:  "a\xffb",
+				POSIX: "'a\xffb'",
+				Bash:  `$'a\xffb'`,
+				Zsh:   `$'a\xffb'`,
+				Fish:  `'a'\xff'b'`,
+			},
+		},
+		"unicode": {
+			s: "naïve café.txt",
+			want: map[Style]string{
+				POSIX:      "'naïve café.txt'",
+				Bash:       "'naïve café.txt'",
+				Fish:       "'naïve café.txt'",
+				PowerShell: "'naïve café.txt'",
+			},
+		},
+		"powershell_typographic_quote": {
+			s: "it’s",
+			want: map[Style]string{
+				POSIX:      "'it’s'",
+				PowerShell: "'it’’s'",
+			},
+		},
+		"powershell_leading_dash": {
+			s: "-file",
+			want: map[Style]string{
+				POSIX:      "-file",
+				PowerShell: "'-file'",
+			},
+		},
+		"powershell_windows_path": {
+			s: `C:\Users\me\file.txt`,
+			want: map[Style]string{
+				POSIX:      `'C:\Users\me\file.txt'`,
+				PowerShell: `C:\Users\me\file.txt`,
+			},
+		},
+		"powershell_comma": {
+			s: `C:\a,b`,
+			want: map[Style]string{
+				POSIX:      `'C:\a,b'`,
+				PowerShell: `'C:\a,b'`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			for style, want := range test.want {
+				if got := Quote(test.s, style); got != want {
+					tt.Fatalf("expected %v quoting %q, got %q", style, want, got)
+				}
+			}
+		})
+	}
+}
+
+// TestQuoteRoundTrip evaluates quoted strings with the shells that are installed and checks that
+// they produce the original string.
+func TestQuoteRoundTrip(t *testing.T) {
+	inputs := []string{
+		"plain",
+		"",
+		"a b",
+		"it's",
+		`"double"`,
+		`back\slash`,
+		"$HOME `id` $(id)",
+		"*?[a]{b,c}~!#;&|<>()",
+		"line\nbreak",
+		"tab\there",
+		"\x1b[31mred",
+		"\x01f",
+		"a\xffb\xfe",
+		"trailing\\",
+		"naïve café",
+		"it’s",
+		"-dash",
+	}
+
+	shells := []struct {
+		style Style
+		name  string
+		args  func(quoted string) []string
+	}{
+		{style: POSIX, name: "sh", args: func(q string) []string { return []string{"-c", "printf '%s' " + q} }},
+		{style: POSIX, name: "dash", args: func(q string) []string { return []string{"-c", "printf '%s' " + q} }},
+		{style: Bash, name: "bash", args: func(q string) []string { return []string{"-c", "printf '%s' " + q} }},
+		{style: Zsh, name: "zsh", args: func(q string) []string { return []string{"-f", "-c", "printf '%s' " + q} }},
+		{style: Fish, name: "fish", args: func(q string) []string { return []string{"--no-config", "-c", "printf '%s' " + q} }},
+	}
+
+	for _, shell := range shells {
+		shell := shell
+		t.Run(shell.name, func(tt *testing.T) {
+			path, err := exec.LookPath(shell.name)
+			if err != nil {
+				tt.Skipf("%s is not installed", shell.name)
+			}
+			for _, input := range inputs {
+				quoted := Quote(input, shell.style)
+				out, err := exec.Command(path, shell.args(quoted)...).Output()
+				if err != nil {
+					tt.Fatalf("failed to evaluate %q: %v", quoted, err)
+				}
+				if string(out) != input {
+					tt.Fatalf("expected %q to evaluate to %q, got %q", quoted, input, string(out))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !windows
+
+package quote
+
+// Default is the quoting style used unless another is selected.
+const Default = POSIX
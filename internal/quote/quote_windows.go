@@ -0,0 +1,7 @@
+//go:build windows
+
+package quote
+
+// Default is the quoting style used unless another is selected. Paths are not quoted by default
+// on Windows, where spaces are common and quoting depends on the shell in use.
+const Default = None
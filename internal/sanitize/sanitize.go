@@ -1,9 +0,0 @@
-//go:build !windows
-
-package sanitize
-
-import "strings"
-
-func SanitizeOutputPath(s string) string {
-	return strings.Replace(s, " ", "\\ ", -1)
-}
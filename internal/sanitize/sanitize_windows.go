@@ -1,7 +0,0 @@
-//go:build windows
-
-package sanitize
-
-func SanitizeOutputPath(s string) string {
-	return s
-}
@@ -0,0 +1,319 @@
+// Package trash moves entries to a trash directory and restores or purges them following the
+// FreeDesktop.org Trash specification (https://specifications.freedesktop.org/trash-spec/).
+//
+// Entries are trashed to the home trash, $XDG_DATA_HOME/Trash, when they are on the same
+// filesystem. Entries on other filesystems are trashed to $topdir/.Trash/$uid, when the
+// administrator has created a suitable $topdir/.Trash directory, or to $topdir/.Trash-$uid, where
+// $topdir is the mount point of the filesystem.
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dkaslovsky/nav/internal/xdg"
+)
+
+const (
+	filesDir  = "files"
+	infoDir   = "info"
+	infoExt   = ".trashinfo"
+	infoGroup = "[Trash Info]"
+
+	// dateLayout is the format of the DeletionDate key, which is in local time.
+	dateLayout = "2006-01-02T15:04:05"
+)
+
+// Trash is a trash directory containing the trashed entries in files and a .trashinfo file for
+// each entry in info.
+type Trash struct {
+	Dir string
+	// topdir is the mount point of the filesystem of a per-mount trash, against which the
+	// original paths of entries are recorded, or empty for the home trash.
+	topdir string
+}
+
+// Item is an entry in a trash directory.
+type Item struct {
+	Name         string // Name of the entry in the files directory.
+	Path         string // Original absolute path of the entry.
+	DeletionDate time.Time
+	Trash        *Trash
+}
+
+// Home returns the home trash.
+func Home() (*Trash, error) {
+	dir, err := xdg.DataHome()
+	if err != nil {
+		return nil, err
+	}
+	return &Trash{Dir: filepath.Join(dir, "Trash")}, nil
+}
+
+// ForPath returns the trash to which the entry at path is moved: the home trash if it is on the
+// same filesystem and otherwise the trash of the filesystem's mount point.
+func ForPath(path string) (*Trash, error) {
+	home, err := Home()
+	if err != nil {
+		return nil, err
+	}
+
+	dev, err := device(path)
+	if err != nil {
+		return nil, err
+	}
+	homeDev, err := device(existingAncestor(home.Dir))
+	if err != nil {
+		return nil, err
+	}
+	if dev == homeDev {
+		return home, nil
+	}
+
+	topdir, err := mountPoint(path, dev)
+	if err != nil {
+		return nil, err
+	}
+	return ForTopdir(topdir, os.Getuid()), nil
+}
+
+// ForTopdir returns the trash of the filesystem mounted at topdir for the user with the given
+// uid. The shared $topdir/.Trash directory is only used if it is a directory, not a symlink, and
+// has the sticky bit set, as required by the specification.
+func ForTopdir(topdir string, uid int) *Trash {
+	shared := filepath.Join(topdir, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&fs.ModeSticky != 0 {
+		return &Trash{Dir: filepath.Join(shared, strconv.Itoa(uid)), topdir: topdir}
+	}
+	return &Trash{Dir: filepath.Join(topdir, fmt.Sprintf(".Trash-%d", uid)), topdir: topdir}
+}
+
+// Put moves the entry at path to the trash.
+func (t *Trash) Put(path string) (*Item, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{filepath.Join(t.Dir, filesDir), filepath.Join(t.Dir, infoDir)} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	item := &Item{Path: path, DeletionDate: time.Now().Truncate(time.Second), Trash: t}
+
+	// Creating the info file exclusively reserves the name in the trash.
+	base := filepath.Base(path)
+	for i := 1; ; i++ {
+		item.Name = base
+		if i > 1 {
+			item.Name = fmt.Sprintf("%s.%d", base, i)
+		}
+		err := t.writeInfo(item)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+	}
+
+	if _, err := os.Lstat(t.filesPath(item)); err == nil {
+		_ = os.Remove(t.infoPath(item))
+		return nil, fmt.Errorf("%s: %w", t.filesPath(item), fs.ErrExist)
+	}
+	if err := os.Rename(path, t.filesPath(item)); err != nil {
+		_ = os.Remove(t.infoPath(item))
+		return nil, err
+	}
+	return item, nil
+}
+
+// List returns the items in the trash, most recently deleted first. Items with missing or
+// malformed info files are skipped.
+func (t *Trash) List() ([]*Item, error) {
+	infos, err := os.ReadDir(filepath.Join(t.Dir, infoDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*Item{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*Item{}
+	for _, info := range infos {
+		name, ok := strings.CutSuffix(info.Name(), infoExt)
+		if !ok || info.IsDir() {
+			continue
+		}
+		item, err := t.readInfo(name)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Lstat(t.filesPath(item)); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].DeletionDate.Equal(items[j].DeletionDate) {
+			return items[i].DeletionDate.After(items[j].DeletionDate)
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+// Restore moves an item back to its original path, creating missing parent directories. An entry
+// that has since been created at the original path is not overwritten.
+func (t *Trash) Restore(item *Item) error {
+	if _, err := os.Lstat(item.Path); err == nil {
+		return fmt.Errorf("%s: %w", item.Path, fs.ErrExist)
+	}
+	if err := os.MkdirAll(filepath.Dir(item.Path), 0o777); err != nil {
+		return err
+	}
+	if err := os.Rename(t.filesPath(item), item.Path); err != nil {
+		return err
+	}
+	return os.Remove(t.infoPath(item))
+}
+
+// Purge permanently deletes an item.
+func (t *Trash) Purge(item *Item) error {
+	if err := os.RemoveAll(t.filesPath(item)); err != nil {
+		return err
+	}
+	return os.Remove(t.infoPath(item))
+}
+
+func (t *Trash) filesPath(item *Item) string {
+	return filepath.Join(t.Dir, filesDir, item.Name)
+}
+
+func (t *Trash) infoPath(item *Item) string {
+	return filepath.Join(t.Dir, infoDir, item.Name+infoExt)
+}
+
+// writeInfo exclusively creates the info file of an item. Original paths in per-mount trashes
+// are recorded relative to the mount point.
+func (t *Trash) writeInfo(item *Item) error {
+	path := item.Path
+	if t.topdir != "" {
+		if rel, err := filepath.Rel(t.topdir, path); err == nil {
+			path = rel
+		}
+	}
+	escaped := (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath()
+
+	f, err := os.OpenFile(t.infoPath(item), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s\nPath=%s\nDeletionDate=%s\n", infoGroup, escaped, item.DeletionDate.Format(dateLayout))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(t.infoPath(item))
+	}
+	return err
+}
+
+func (t *Trash) readInfo(name string) (*Item, error) {
+	item := &Item{Name: name, Trash: t}
+	f, err := os.Open(t.infoPath(item))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	inGroup := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == infoGroup
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !inGroup || !found {
+			continue
+		}
+		switch key {
+		case "Path":
+			path, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, err
+			}
+			item.Path = filepath.FromSlash(path)
+			if !filepath.IsAbs(item.Path) {
+				if t.topdir == "" {
+					return nil, fmt.Errorf("relative path %q in home trash", path)
+				}
+				item.Path = filepath.Join(t.topdir, item.Path)
+			}
+		case "DeletionDate":
+			item.DeletionDate, err = time.ParseInLocation(dateLayout, value, time.Local)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if item.Path == "" {
+		return nil, errors.New("missing Path key")
+	}
+	return item, nil
+}
+
+// existingAncestor returns path or its closest ancestor that exists.
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// mountPoint returns the topmost ancestor of path on the device dev.
+func mountPoint(path string, dev uint64) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for {
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path, nil
+		}
+		parentDev, err := device(parent)
+		if err != nil {
+			return "", err
+		}
+		if parentDev != dev {
+			return path, nil
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,169 @@
+package trash
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeFile(tt *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		tt.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		tt.Fatal(err)
+	}
+}
+
+func TestPutListRestorePurge(t *testing.T) {
+	tests := map[string]struct {
+		topdir bool
+	}{
+		"home":      {topdir: false},
+		"per_mount": {topdir: true},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			root := tt.TempDir()
+			tt.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+
+			trash, err := Home()
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if test.topdir {
+				trash = ForTopdir(root, 1000)
+			}
+
+			// Two entries with the same name are trashed under unique names.
+			paths := []string{filepath.Join(root, "a", "file name"), filepath.Join(root, "b", "file name")}
+			for i, path := range paths {
+				writeFile(tt, path, strings.Repeat("x", i))
+				if _, err := trash.Put(path); err != nil {
+					tt.Fatalf("unexpected error: %v", err)
+				}
+				if _, err := os.Lstat(path); err == nil {
+					tt.Fatalf("expected %s to be removed", path)
+				}
+			}
+
+			items, err := trash.List()
+			if err != nil {
+				tt.Fatalf("unexpected error: %v", err)
+			}
+			if len(items) != 2 {
+				tt.Fatalf("expected 2 items, got %d", len(items))
+			}
+			names := map[string]string{}
+			for _, item := range items {
+				names[item.Path] = item.Name
+			}
+			if names[paths[0]] != "file name" || names[paths[1]] != "file name.2" {
+				tt.Fatalf("unexpected trashed names %v", names)
+			}
+
+			// Restoring does not overwrite an entry created at the original path.
+			writeFile(tt, paths[0], "new")
+			var first *Item
+			for _, item := range items {
+				if item.Path == paths[0] {
+					first = item
+				}
+			}
+			if err := trash.Restore(first); err == nil {
+				tt.Fatal("expected error restoring over an existing entry")
+			}
+			if err := os.Remove(paths[0]); err != nil {
+				tt.Fatal(err)
+			}
+			if err := trash.Restore(first); err != nil {
+				tt.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := os.Lstat(paths[0]); err != nil {
+				tt.Fatalf("expected %s to be restored", paths[0])
+			}
+
+			items, err = trash.List()
+			if err != nil || len(items) != 1 {
+				tt.Fatalf("expected 1 item, got %d (%v)", len(items), err)
+			}
+			if err := trash.Purge(items[0]); err != nil {
+				tt.Fatalf("unexpected error: %v", err)
+			}
+			if items, _ = trash.List(); len(items) != 0 {
+				tt.Fatalf("expected empty trash, got %d items", len(items))
+			}
+			if _, err := os.Lstat(filepath.Join(trash.Dir, filesDir, "file name.2")); err == nil {
+				tt.Fatal("expected purged entry to be removed")
+			}
+		})
+	}
+}
+
+func TestInfoFile(t *testing.T) {
+	root := t.TempDir()
+	trash := ForTopdir(root, 1000)
+	path := filepath.Join(root, "dir", "100% done")
+	writeFile(t, path, "")
+
+	item, err := trash.Put(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.ReadFile(filepath.Join(trash.Dir, infoDir, item.Name+infoExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[Trash Info]\nPath=dir/100%25%20done\nDeletionDate=" + item.DeletionDate.Format(dateLayout) + "\n"
+	if string(info) != want {
+		t.Fatalf("expected info file %q, got %q", want, string(info))
+	}
+}
+
+func TestForTopdir(t *testing.T) {
+	tests := map[string]struct {
+		mode fs.FileMode
+		want string
+	}{
+		"no_shared_trash": {
+			want: ".Trash-1000",
+		},
+		"shared_trash": {
+			mode: fs.ModeDir | fs.ModeSticky | 0o777,
+			want: filepath.Join(".Trash", "1000"),
+		},
+		"shared_trash_without_sticky_bit": {
+			mode: fs.ModeDir | 0o777,
+			want: ".Trash-1000",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			if runtime.GOOS == "windows" && test.mode&fs.ModeSticky != 0 {
+				tt.Skip("sticky bit is not supported on windows")
+			}
+
+			topdir := tt.TempDir()
+			if test.mode != 0 {
+				shared := filepath.Join(topdir, ".Trash")
+				if err := os.Mkdir(shared, 0o777); err != nil {
+					tt.Fatal(err)
+				}
+				if err := os.Chmod(shared, test.mode); err != nil {
+					tt.Fatal(err)
+				}
+			}
+
+			if got := ForTopdir(topdir, 1000).Dir; got != filepath.Join(topdir, test.want) {
+				tt.Fatalf("expected %s, got %s", filepath.Join(topdir, test.want), got)
+			}
+		})
+	}
+}
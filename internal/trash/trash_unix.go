@@ -0,0 +1,22 @@
+//go:build !windows
+
+package trash
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// device returns the identifier of the filesystem containing path without following symlinks.
+func device(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("%s: no device information available", path)
+	}
+	return uint64(stat.Dev), nil // Dev is a signed integer on darwin.
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package trash
+
+// device treats all paths as being on the same filesystem, so that entries are always moved to
+// the home trash.
+func device(path string) (uint64, error) {
+	return 0, nil
+}
@@ -0,0 +1,65 @@
+// Package watch reports changes to the entries of a set of directories. Changes are collected for
+// an interval after the first change and reported together, so that a burst of changes, such as
+// the files written by a build, results in a single report.
+package watch
+
+import (
+	"sort"
+	"time"
+)
+
+// Watcher watches a set of directories for entries that are created, removed, renamed, or
+// written.
+type Watcher struct {
+	backend
+	delay   time.Duration
+	changes chan string   // Directories with changes, as they are read.
+	events  chan []string // Directories with changes, collected over the delay.
+}
+
+// Events returns the channel receiving the sorted directories whose entries changed. The channel is
+// closed when the watcher is closed.
+func (w *Watcher) Events() <-chan []string {
+	return w.events
+}
+
+// collect reports the directories received on the changes channel once delay has passed since the
+// first unreported change.
+func (w *Watcher) collect() {
+	defer close(w.events)
+
+	pending := make(map[string]bool)
+	var (
+		timer <-chan time.Time
+		out   chan<- []string // Set while a report is waiting to be received.
+		dirs  []string
+	)
+	for {
+		select {
+		case dir, ok := <-w.changes:
+			if !ok {
+				return
+			}
+			pending[dir] = true
+			if timer == nil && out == nil {
+				timer = time.After(w.delay)
+			}
+
+		case <-timer:
+			timer = nil
+			dirs = make([]string, 0, len(pending))
+			for dir := range pending {
+				dirs = append(dirs, dir)
+			}
+			sort.Strings(dirs)
+			pending = make(map[string]bool)
+			out = w.events
+
+		case out <- dirs:
+			out, dirs = nil, nil
+			if len(pending) != 0 {
+				timer = time.After(w.delay)
+			}
+		}
+	}
+}
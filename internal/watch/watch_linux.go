@@ -0,0 +1,157 @@
+//go:build linux
+
+package watch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchEvents are the inotify events reported as changes to the entries of a directory.
+const watchEvents = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_ATTRIB | unix.IN_CLOSE_WRITE | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF | unix.IN_ONLYDIR
+
+// backend watches directories with inotify.
+type backend struct {
+	fd int
+	f  *os.File // Inotify instance, read through the runtime poller so that closing it stops reads.
+
+	mu     sync.Mutex
+	dirs   map[int]string  // Map watch descriptor to directory.
+	wds    map[string]int  // Map directory to watch descriptor.
+	failed map[string]bool // Directories that could not be watched while in the set.
+}
+
+// New returns a watcher that reports changes delay after the first unreported change.
+func New(delay time.Duration) (*Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		backend: backend{
+			fd:     fd,
+			f:      os.NewFile(uintptr(fd), "inotify"),
+			dirs:   make(map[int]string),
+			wds:    make(map[string]int),
+			failed: make(map[string]bool),
+		},
+		delay:   delay,
+		changes: make(chan string, 64),
+		events:  make(chan []string, 1),
+	}
+	go w.read()
+	go w.collect()
+	return w, nil
+}
+
+// Set replaces the watched directories with dirs. Directories that cannot be watched, for example
+// because they no longer exist, are skipped and the first error is returned. They are not tried
+// again until they have left the set.
+func (w *Watcher) Set(dirs []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	keep := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		keep[filepath.Clean(dir)] = true
+	}
+
+	var firstErr error
+	for dir, wd := range w.wds {
+		if keep[dir] {
+			continue
+		}
+		// The watch may already be gone with its directory.
+		_, _ = unix.InotifyRmWatch(w.fd, uint32(wd))
+		delete(w.wds, dir)
+		delete(w.dirs, wd)
+	}
+	for dir := range w.failed {
+		if !keep[dir] {
+			delete(w.failed, dir)
+		}
+	}
+	for dir := range keep {
+		if _, ok := w.wds[dir]; ok || w.failed[dir] {
+			continue
+		}
+		wd, err := unix.InotifyAddWatch(w.fd, dir, watchEvents)
+		if err != nil {
+			w.failed[dir] = true
+			if firstErr == nil {
+				firstErr = &os.PathError{Op: "watch", Path: dir, Err: err}
+			}
+			continue
+		}
+		// Watching the same directory through another path returns the existing descriptor.
+		if prev, ok := w.dirs[wd]; ok {
+			delete(w.wds, prev)
+		}
+		w.wds[dir] = wd
+		w.dirs[wd] = dir
+	}
+	return firstErr
+}
+
+// Close stops watching and closes the events channel.
+func (w *Watcher) Close() error {
+	return w.f.Close()
+}
+
+// read sends the directory of each event on the changes channel until the watcher is closed.
+func (w *Watcher) read() {
+	defer close(w.changes)
+
+	var buf [64 * (unix.SizeofInotifyEvent + unix.NAME_MAX + 1)]byte
+	for {
+		n, err := w.f.Read(buf[:])
+		if err != nil {
+			if errors.Is(err, os.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			offset += unix.SizeofInotifyEvent + int(event.Len)
+
+			if event.Mask&unix.IN_Q_OVERFLOW != 0 {
+				// Events were dropped, so every directory may have changed.
+				for _, dir := range w.watched() {
+					w.changes <- dir
+				}
+				continue
+			}
+
+			w.mu.Lock()
+			dir, ok := w.dirs[int(event.Wd)]
+			if event.Mask&unix.IN_IGNORED != 0 && ok {
+				delete(w.dirs, int(event.Wd))
+				delete(w.wds, dir)
+			}
+			w.mu.Unlock()
+			if ok {
+				w.changes <- dir
+			}
+		}
+	}
+}
+
+// watched returns the watched directories.
+func (w *Watcher) watched() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	dirs := make([]string, 0, len(w.wds))
+	for dir := range w.wds {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package watch
+
+import (
+	"errors"
+	"time"
+)
+
+// backend is empty where watching is not supported.
+type backend struct{}
+
+// New returns an error where watching is not supported.
+func New(delay time.Duration) (*Watcher, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// Set does nothing where watching is not supported.
+func (w *Watcher) Set(dirs []string) error {
+	return nil
+}
+
+// Close does nothing where watching is not supported.
+func (w *Watcher) Close() error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package watch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	w, err := New(10 * time.Millisecond)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("watching is not supported")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := t.TempDir(), t.TempDir()
+	if err := w.Set([]string{a, b}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes in both directories are reported together.
+	for _, file := range []string{filepath.Join(a, "x"), filepath.Join(a, "y"), filepath.Join(b, "x")} {
+		if err := os.WriteFile(file, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{a, b}
+	if b < a {
+		want = []string{b, a}
+	}
+	select {
+	case dirs := <-w.Events():
+		if !reflect.DeepEqual(dirs, want) {
+			t.Fatalf("expected changes in %v, got %v", want, dirs)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected changes to be reported")
+	}
+
+	// Directories that are no longer watched are not reported.
+	if err := w.Set([]string{b}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(a, "x")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case dirs := <-w.Events():
+		t.Fatalf("unexpected changes in %v", dirs)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// A directory that cannot be watched is not tried again while it stays in the set.
+	missing := filepath.Join(a, "missing")
+	if err := w.Set([]string{missing}); err == nil {
+		t.Fatal("expected error watching a missing directory")
+	}
+	if err := os.Mkdir(missing, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Set([]string{missing}); err != nil {
+		t.Fatalf("expected the directory not to be watched again, got %v", err)
+	}
+	if len(w.watched()) != 0 {
+		t.Fatalf("expected no watched directories, got %v", w.watched())
+	}
+	if err := w.Set(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Set([]string{missing}); err != nil || len(w.watched()) != 1 {
+		t.Fatalf("expected the directory to be watched once it is added again, got %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case _, ok := <-w.Events():
+		if ok {
+			t.Fatal("expected the events channel to be closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the events channel to be closed")
+	}
+}
@@ -0,0 +1,36 @@
+// Package xdg resolves user directories following the XDG Base Directory Specification.
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigHome returns $XDG_CONFIG_HOME, falling back to ~/.config.
+func ConfigHome() (string, error) {
+	return baseDir("XDG_CONFIG_HOME", ".config")
+}
+
+// DataHome returns $XDG_DATA_HOME, falling back to ~/.local/share.
+func DataHome() (string, error) {
+	return baseDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// CacheHome returns $XDG_CACHE_HOME, falling back to ~/.cache.
+func CacheHome() (string, error) {
+	return baseDir("XDG_CACHE_HOME", ".cache")
+}
+
+// baseDir returns the directory named by the environment variable env if it is set to an absolute
+// path, as required by the specification, and otherwise the fallback path relative to the home
+// directory.
+func baseDir(env string, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback), nil
+}
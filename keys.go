@@ -2,6 +2,8 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"unicode"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -19,8 +21,10 @@ var (
 	keyFileSeparator = key.NewBinding(key.WithKeys(fileSeparator))
 	keySpace         = key.NewBinding(key.WithKeys(" "))
 
-	keyMark    = key.NewBinding(key.WithKeys("ctrl+v"))
-	keyMarkAll = key.NewBinding(key.WithKeys("ctrl+a"))
+	keyMark        = key.NewBinding(key.WithKeys("ctrl+v"))
+	keyMarkAll     = key.NewBinding(key.WithKeys("ctrl+a"))
+	keyMarkSubtree = key.NewBinding(key.WithKeys("ctrl+e"))
+	keyModeBasket  = key.NewBinding(key.WithKeys("v"))
 
 	keyUp    = key.NewBinding(key.WithKeys("up", "k"))
 	keyDown  = key.NewBinding(key.WithKeys("down", "j"))
@@ -34,15 +38,204 @@ var (
 	keyModeSearch  = key.NewBinding(key.WithKeys("i"))
 	keySearchSlash = key.NewBinding(key.WithKeys("/"))
 
+	keySearchStrategy = key.NewBinding(key.WithKeys("ctrl+s"))
+	keySearchPaths    = key.NewBinding(key.WithKeys("ctrl+p"))
+	keyToggleFlat     = key.NewBinding(key.WithKeys("ctrl+f"))
+
 	keyToggleFollowSymlink = key.NewBinding(key.WithKeys("f"))
 	keyToggleHidden        = key.NewBinding(key.WithKeys("a"))
+	keyToggleIgnored       = key.NewBinding(key.WithKeys("I"))
 	keyToggleList          = key.NewBinding(key.WithKeys("L"))
 	keyToggleTree          = key.NewBinding(key.WithKeys("t"))
 	keyToggleExpand        = key.NewBinding(key.WithKeys("m"))
+	keyTogglePreview       = key.NewBinding(key.WithKeys("p"))
+
+	keySort        = key.NewBinding(key.WithKeys("s"))
+	keySortReverse = key.NewBinding(key.WithKeys("S"))
+
+	keyRename  = key.NewBinding(key.WithKeys("R"))
+	keyNewFile = key.NewBinding(key.WithKeys("n"))
+	keyNewDir  = key.NewBinding(key.WithKeys("N"))
+	keyCopy    = key.NewBinding(key.WithKeys("y"))
+	keyMove    = key.NewBinding(key.WithKeys("M"))
+	keyDelete  = key.NewBinding(key.WithKeys("D"))
+
+	keyModeTrash = key.NewBinding(key.WithKeys("T"))
+	keyRestore   = key.NewBinding(key.WithKeys("r"))
+
+	keyAddBookmark   = key.NewBinding(key.WithKeys("b"))
+	keyModeBookmarks = key.NewBinding(key.WithKeys("'"))
+	keyJumpBookmark  = key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"))
+
+	keyModeFrecency = key.NewBinding(key.WithKeys("z"))
+
+	keyHistoryBack    = key.NewBinding(key.WithKeys("["))
+	keyHistoryForward = key.NewBinding(key.WithKeys("]"))
+	keyModeHistory    = key.NewBinding(key.WithKeys("ctrl+o"))
+
+	keyNewTab       = key.NewBinding(key.WithKeys("ctrl+t"))
+	keyCloseTab     = key.NewBinding(key.WithKeys("ctrl+w"))
+	keyNextTab      = key.NewBinding(key.WithKeys("tab"))
+	keyPrevTab      = key.NewBinding(key.WithKeys("shift+tab"))
+	keyMoveTabLeft  = key.NewBinding(key.WithKeys("<"))
+	keyMoveTabRight = key.NewBinding(key.WithKeys(">"))
+
+	keyToggleDualPane = key.NewBinding(key.WithKeys("|"))
+	keySwitchPane     = key.NewBinding(key.WithKeys("o"))
+	keyComparePanes   = key.NewBinding(key.WithKeys("="))
+	keyReturnPanes    = key.NewBinding(key.WithKeys("ctrl+y"))
 
 	keyDismissError = key.NewBinding(key.WithKeys("e"))
 )
 
+// keyScope is a bitmask of the modes in which a key binding is active.
+type keyScope uint8
+
+const (
+	keyScopeNormal keyScope = 1 << iota
+	keyScopeSearch
+	keyScopeHelp
+	keyScopeError
+	keyScopePrompt
+	keyScopeList // Views that replace the directory listing, such as the trash browser.
+
+	keyScopeAll = keyScopeNormal | keyScopeSearch | keyScopeHelp | keyScopeError | keyScopePrompt | keyScopeList
+)
+
+// keyAction names a remappable key binding. Bindings for different actions conflict when they
+// share a key and are active in a common scope.
+type keyAction struct {
+	name    string
+	binding *key.Binding
+	scope   keyScope
+}
+
+// keyActions lists every remappable binding.
+var keyActions = []*keyAction{
+	{name: "quit", binding: &keyQuit, scope: keyScopeAll},
+	{name: "return-dir", binding: &keyReturnDirectory, scope: keyScopeNormal | keyScopeSearch},
+	{name: "return-selected", binding: &keyReturnSelected, scope: keyScopeNormal | keyScopeSearch},
+
+	{name: "esc", binding: &keyEsc, scope: keyScopeNormal | keyScopeSearch | keyScopeHelp | keyScopePrompt | keyScopeList},
+	{name: "select", binding: &keySelect, scope: keyScopeNormal | keyScopeSearch | keyScopePrompt | keyScopeList},
+	{name: "back", binding: &keyBack, scope: keyScopeNormal | keyScopeSearch | keyScopePrompt},
+	{name: "complete", binding: &keyTab, scope: keyScopeSearch},
+	{name: "separator", binding: &keyFileSeparator, scope: keyScopeSearch},
+	{name: "space", binding: &keySpace, scope: keyScopeSearch},
+
+	{name: "mark", binding: &keyMark, scope: keyScopeNormal},
+	{name: "mark-all", binding: &keyMarkAll, scope: keyScopeNormal},
+	{name: "mark-subtree", binding: &keyMarkSubtree, scope: keyScopeNormal},
+	{name: "basket", binding: &keyModeBasket, scope: keyScopeNormal | keyScopeList},
+
+	{name: "up", binding: &keyUp, scope: keyScopeNormal | keyScopeSearch | keyScopeList},
+	{name: "down", binding: &keyDown, scope: keyScopeNormal | keyScopeSearch | keyScopeList},
+	{name: "left", binding: &keyLeft, scope: keyScopeNormal | keyScopeSearch},
+	{name: "right", binding: &keyRight, scope: keyScopeNormal | keyScopeSearch},
+
+	{name: "bottom", binding: &keyGotoBottom, scope: keyScopeNormal},
+	{name: "top", binding: &keyGotoTop, scope: keyScopeNormal},
+
+	{name: "help", binding: &keyModeHelp, scope: keyScopeNormal | keyScopeHelp},
+	{name: "search", binding: &keyModeSearch, scope: keyScopeNormal},
+	{name: "search-slash", binding: &keySearchSlash, scope: keyScopeNormal},
+	{name: "search-strategy", binding: &keySearchStrategy, scope: keyScopeSearch},
+	{name: "search-paths", binding: &keySearchPaths, scope: keyScopeSearch},
+	{name: "toggle-flat", binding: &keyToggleFlat, scope: keyScopeNormal | keyScopeSearch},
+
+	{name: "toggle-follow", binding: &keyToggleFollowSymlink, scope: keyScopeNormal},
+	{name: "toggle-hidden", binding: &keyToggleHidden, scope: keyScopeNormal},
+	{name: "toggle-ignored", binding: &keyToggleIgnored, scope: keyScopeNormal},
+	{name: "toggle-list", binding: &keyToggleList, scope: keyScopeNormal},
+	{name: "toggle-tree", binding: &keyToggleTree, scope: keyScopeNormal},
+	{name: "toggle-expand", binding: &keyToggleExpand, scope: keyScopeNormal},
+	{name: "toggle-preview", binding: &keyTogglePreview, scope: keyScopeNormal},
+
+	{name: "sort", binding: &keySort, scope: keyScopeNormal},
+	{name: "sort-reverse", binding: &keySortReverse, scope: keyScopeNormal},
+
+	{name: "rename", binding: &keyRename, scope: keyScopeNormal},
+	{name: "new-file", binding: &keyNewFile, scope: keyScopeNormal},
+	{name: "new-dir", binding: &keyNewDir, scope: keyScopeNormal},
+	{name: "copy", binding: &keyCopy, scope: keyScopeNormal},
+	{name: "move", binding: &keyMove, scope: keyScopeNormal},
+	{name: "delete", binding: &keyDelete, scope: keyScopeNormal | keyScopeList},
+
+	{name: "trash", binding: &keyModeTrash, scope: keyScopeNormal | keyScopeList},
+	{name: "restore", binding: &keyRestore, scope: keyScopeList},
+
+	{name: "bookmark", binding: &keyAddBookmark, scope: keyScopeNormal},
+	{name: "bookmarks", binding: &keyModeBookmarks, scope: keyScopeNormal | keyScopeList},
+	{name: "jump-bookmark", binding: &keyJumpBookmark, scope: keyScopeNormal | keyScopeList},
+
+	{name: "frecent", binding: &keyModeFrecency, scope: keyScopeNormal | keyScopeList},
+
+	{name: "history-back", binding: &keyHistoryBack, scope: keyScopeNormal},
+	{name: "history-forward", binding: &keyHistoryForward, scope: keyScopeNormal},
+	{name: "history", binding: &keyModeHistory, scope: keyScopeNormal | keyScopeList},
+
+	{name: "new-tab", binding: &keyNewTab, scope: keyScopeNormal},
+	{name: "close-tab", binding: &keyCloseTab, scope: keyScopeNormal},
+	{name: "next-tab", binding: &keyNextTab, scope: keyScopeNormal},
+	{name: "prev-tab", binding: &keyPrevTab, scope: keyScopeNormal},
+	{name: "move-tab-left", binding: &keyMoveTabLeft, scope: keyScopeNormal},
+	{name: "move-tab-right", binding: &keyMoveTabRight, scope: keyScopeNormal},
+
+	{name: "dual-pane", binding: &keyToggleDualPane, scope: keyScopeNormal},
+	{name: "switch-pane", binding: &keySwitchPane, scope: keyScopeNormal},
+	{name: "compare-panes", binding: &keyComparePanes, scope: keyScopeNormal},
+	{name: "return-panes", binding: &keyReturnPanes, scope: keyScopeNormal},
+
+	{name: "dismiss-error", binding: &keyDismissError, scope: keyScopeError},
+}
+
+// bindKey replaces the keys bound to the named action.
+func bindKey(action string, keys []string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("no keys provided for action %q", action)
+	}
+	for _, k := range keys {
+		if k == "" {
+			return fmt.Errorf("empty key provided for action %q", action)
+		}
+	}
+	for _, a := range keyActions {
+		if a.name == action {
+			*a.binding = key.NewBinding(key.WithKeys(keys...))
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown key action %q", action)
+}
+
+// parseKeyBinding parses and applies a binding of the form action=key[,key...].
+func parseKeyBinding(s string) error {
+	action, keys, found := strings.Cut(s, "=")
+	if !found {
+		return fmt.Errorf("invalid key binding %q: expected action=key", s)
+	}
+	return bindKey(strings.TrimSpace(action), strings.Split(keys, ","))
+}
+
+// checkKeyConflicts returns an error if a key is bound to more than one action in the same scope.
+func checkKeyConflicts() error {
+	for i, a := range keyActions {
+		for _, b := range keyActions[i+1:] {
+			if a.scope&b.scope == 0 {
+				continue
+			}
+			for _, ak := range a.binding.Keys() {
+				for _, bk := range b.binding.Keys() {
+					if ak == bk {
+						return fmt.Errorf("key %q is bound to both %q and %q", ak, a.name, b.name)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
 type remappedEscKey struct {
 	key     key.Binding
 	presses int
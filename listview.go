@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// listView is a scrollable list of lines with a cursor, used by views that replace the directory
+// listing such as the trash browser.
+type listView struct {
+	title  string
+	lines  []string
+	idx    int
+	offset int
+}
+
+// setLines replaces the lines of the list, keeping the cursor in bounds.
+func (v *listView) setLines(lines []string) {
+	v.lines = lines
+	if v.idx >= len(v.lines) {
+		v.idx = max(0, len(v.lines)-1)
+	}
+}
+
+func (v *listView) moveUp() {
+	v.idx--
+	if v.idx < 0 {
+		v.idx = max(0, len(v.lines)-1) // wrap
+	}
+}
+
+func (v *listView) moveDown() {
+	v.idx++
+	if v.idx >= len(v.lines) {
+		v.idx = 0 // wrap
+	}
+}
+
+// render returns the lines visible in a viewport of the given size, scrolling to keep the cursor
+// in view.
+func (v *listView) render(width int, height int) []string {
+	height = max(height, 1)
+	if v.idx < v.offset {
+		v.offset = v.idx
+	} else if v.idx >= v.offset+height {
+		v.offset = v.idx - height + 1
+	}
+
+	output := []string{}
+	if len(v.lines) == 0 {
+		output = append(output, "\t(no entries)")
+	}
+	end := min(v.offset+height, len(v.lines))
+	for i := v.offset; i < end; i++ {
+		line := lipgloss.NewStyle().MaxWidth(max(width-columnSeparatorLen, 0)).Render(v.lines[i])
+		if w := lipgloss.Width(line); w < width-columnSeparatorLen {
+			line += strings.Repeat(" ", width-columnSeparatorLen-w)
+		}
+		if i == v.idx {
+			output = append(output, cursorRendererSelected.Render(line))
+		} else {
+			output = append(output, cursorRendererNormal.Render(line))
+		}
+	}
+	return output
+}
+
+// listViewLocationBar renders the title of a list view with the number of lines it contains.
+func (m *model) listViewLocationBar(v *listView) string {
+	if m.modeError {
+		return m.locationBar()
+	}
+	if m.modePrompt {
+		return m.promptBar()
+	}
+	return barRendererLocation.Render(v.title) + barRendererSearchCount.Render(fmt.Sprintf(" (%d)", len(v.lines)))
+}
+
+func (m *model) listViewView(v *listView) string {
+	output := []string{m.listViewLocationBar(v)}
+	output = append(output, v.render(m.screenWidth, m.height-3)...)
+	return strings.Join(output, "\n")
+}
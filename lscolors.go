@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// envLSColors is the environment variable read by ls --color for entry coloring rules.
+const envLSColors = "LS_COLORS"
+
+// entryColorHidden is the type code used by themes for hidden entries. It is not part of the
+// LS_COLORS format, which has no notion of hidden files.
+const entryColorHidden = "hi"
+
+// entryColorLinkTarget is the value of the "ln" type code that colors symlinks as their targets.
+const entryColorLinkTarget = "target"
+
+// entryColors maps entries to SGR parameter strings (for example "01;34") using the rules of
+// LS_COLORS: two-letter file type codes such as "di" and "ln", and "*suffix" rules that apply to
+// regular files.
+type entryColors struct {
+	types      map[string]string
+	suffixes   []entryColorSuffix
+	linkTarget bool // Symlinks are colored as their targets ("ln=target").
+}
+
+type entryColorSuffix struct {
+	suffix string
+	sgr    string
+}
+
+func newEntryColors() *entryColors {
+	return &entryColors{
+		types: make(map[string]string),
+	}
+}
+
+// parseLSColors parses the colon-separated key=value rules of an LS_COLORS value, ignoring
+// malformed rules as ls does.
+func parseLSColors(s string) *entryColors {
+	ec := newEntryColors()
+	for _, rule := range strings.Split(s, ":") {
+		k, sgr, found := strings.Cut(rule, "=")
+		if !found || k == "" {
+			continue
+		}
+		if strings.HasPrefix(k, "*") {
+			ec.suffixes = append(ec.suffixes, entryColorSuffix{suffix: k[1:], sgr: sgr})
+			continue
+		}
+		ec.setType(k, sgr)
+	}
+	return ec
+}
+
+// setType sets the SGR parameters of a type code.
+func (ec *entryColors) setType(code string, sgr string) {
+	if code == "ln" && sgr == entryColorLinkTarget {
+		ec.linkTarget = true
+		delete(ec.types, code)
+		return
+	}
+	if code == "ln" {
+		ec.linkTarget = false
+	}
+	ec.types[code] = sgr
+}
+
+// lookupLink returns the SGR parameters for the symlink at path when symlinks are colored as their
+// targets. A symlink whose target does not exist is colored as an orphan ("or").
+func (ec *entryColors) lookupLink(path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		sgr, ok := ec.types["or"]
+		return sgr, ok
+	}
+	target := &entry{DirEntry: fs.FileInfoToDirEntry(info), info: info}
+	target.setMode()
+	return ec.lookup(info.Name(), target.mode, info)
+}
+
+// lookup returns the SGR parameters for an entry. The file type takes precedence over suffix
+// rules, which only apply to regular files, following the behavior of ls.
+func (ec *entryColors) lookup(name string, mode entryMode, info fs.FileInfo) (string, bool) {
+	for _, code := range entryColorTypes(mode, info) {
+		if sgr, ok := ec.types[code]; ok {
+			return sgr, true
+		}
+	}
+
+	if mode.has(entryModeFile) && !mode.has(entryModeExec) && (info == nil || info.Mode().IsRegular()) {
+		// Later rules take precedence, as with ls.
+		for i := len(ec.suffixes) - 1; i >= 0; i-- {
+			rule := ec.suffixes[i]
+			if len(name) >= len(rule.suffix) && strings.EqualFold(name[len(name)-len(rule.suffix):], rule.suffix) {
+				return rule.sgr, true
+			}
+		}
+	}
+
+	if mode.has(entryModeFile) {
+		if sgr, ok := ec.types["fi"]; ok {
+			return sgr, true
+		}
+	}
+	return "", false
+}
+
+// entryColorTypes returns the type codes that apply to an entry, most specific first.
+func entryColorTypes(mode entryMode, info fs.FileInfo) []string {
+	codes := []string{}
+	if mode.has(entryModeSymlink) {
+		return append(codes, "ln")
+	}
+	if mode.has(entryModeHidden) {
+		codes = append(codes, entryColorHidden)
+	}
+
+	var fm fs.FileMode
+	if info != nil {
+		fm = info.Mode()
+	}
+
+	switch {
+	case mode.has(entryModeDir):
+		switch {
+		case fm&fs.ModeSticky != 0 && fm&0o002 != 0:
+			codes = append(codes, "tw")
+		case fm&0o002 != 0:
+			codes = append(codes, "ow")
+		case fm&fs.ModeSticky != 0:
+			codes = append(codes, "st")
+		}
+		codes = append(codes, "di")
+	case fm&fs.ModeNamedPipe != 0:
+		codes = append(codes, "pi")
+	case fm&fs.ModeSocket != 0:
+		codes = append(codes, "so")
+	case fm&fs.ModeCharDevice != 0:
+		codes = append(codes, "cd")
+	case fm&fs.ModeDevice != 0:
+		codes = append(codes, "bd")
+	default:
+		if fm&fs.ModeSetuid != 0 {
+			codes = append(codes, "su")
+		}
+		if fm&fs.ModeSetgid != 0 {
+			codes = append(codes, "sg")
+		}
+		if mode.has(entryModeExec) {
+			codes = append(codes, "ex")
+		}
+	}
+	return codes
+}
+
+// degrade rewrites all rules for the capabilities of a terminal color profile.
+func (ec *entryColors) degrade(p termenv.Profile) {
+	for k, sgr := range ec.types {
+		ec.types[k] = degradeSGR(sgr, p)
+	}
+	for i := range ec.suffixes {
+		ec.suffixes[i].sgr = degradeSGR(ec.suffixes[i].sgr, p)
+	}
+}
+
+// degradeSGR converts 256-color and truecolor parameters in an SGR parameter string to the closest
+// color supported by a profile. Colors are dropped entirely for the Ascii profile while other
+// attributes such as bold are kept.
+func degradeSGR(sgr string, p termenv.Profile) string {
+	if p == termenv.TrueColor {
+		return sgr
+	}
+
+	params := strings.Split(sgr, ";")
+	out := make([]string, 0, len(params))
+	for i := 0; i < len(params); i++ {
+		param := params[i]
+		code, err := strconv.Atoi(param)
+		if err != nil {
+			out = append(out, param)
+			continue
+		}
+
+		var (
+			c  termenv.Color
+			bg bool
+		)
+		switch {
+		case (code == 38 || code == 48) && i+2 < len(params) && params[i+1] == "5":
+			n, err := strconv.Atoi(params[i+2])
+			if err != nil {
+				return sgr
+			}
+			c, bg = termenv.ANSI256Color(n), code == 48
+			i += 2
+		case (code == 38 || code == 48) && i+4 < len(params) && params[i+1] == "2":
+			rgb := make([]int, 3)
+			for j := range rgb {
+				if rgb[j], err = strconv.Atoi(params[i+2+j]); err != nil {
+					return sgr
+				}
+			}
+			c, bg = termenv.RGBColor(fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])), code == 48
+			i += 4
+		case (30 <= code && code <= 37) || (40 <= code && code <= 47) || (90 <= code && code <= 97) || (100 <= code && code <= 107):
+			if p == termenv.Ascii {
+				continue
+			}
+			out = append(out, param)
+			continue
+		default:
+			out = append(out, param)
+			continue
+		}
+
+		if seq := p.Convert(c).Sequence(bg); seq != "" {
+			out = append(out, seq)
+		}
+	}
+	return strings.Join(out, ";")
+}
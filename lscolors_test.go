@@ -0,0 +1,237 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestParseLSColors(t *testing.T) {
+	file := &mockFileInfo{mode: 0o644}
+	dir := &mockFileInfo{mode: fs.ModeDir | 0o755}
+
+	tests := map[string]struct {
+		lsColors string
+		name     string
+		mode     entryMode
+		info     fs.FileInfo
+		want     string
+		wantOK   bool
+	}{
+		"directory": {
+			lsColors: "di=01;34:ln=01;36",
+			name:     "src",
+			mode:     entryModeDir,
+			info:     dir,
+			want:     "01;34",
+			wantOK:   true,
+		},
+		"other_writable_directory": {
+			lsColors: "di=01;34:ow=34;42",
+			name:     "tmp",
+			mode:     entryModeDir,
+			info:     &mockFileInfo{mode: fs.ModeDir | 0o777},
+			want:     "34;42",
+			wantOK:   true,
+		},
+		"symlink": {
+			lsColors: "di=01;34:ln=01;36",
+			name:     "link",
+			mode:     entryModeSymlink,
+			want:     "01;36",
+			wantOK:   true,
+		},
+		"executable_before_suffix": {
+			lsColors: "ex=01;32:*.sh=33",
+			name:     "run.sh",
+			mode:     entryModeFile | entryModeExec,
+			info:     &mockFileInfo{mode: 0o755},
+			want:     "01;32",
+			wantOK:   true,
+		},
+		"suffix_ignores_case": {
+			lsColors: "*.tar=01;31",
+			name:     "archive.TAR",
+			mode:     entryModeFile,
+			info:     file,
+			want:     "01;31",
+			wantOK:   true,
+		},
+		"later_suffix_wins": {
+			lsColors: "*.gz=31:*.tar.gz=32",
+			name:     "archive.tar.gz",
+			mode:     entryModeFile,
+			info:     file,
+			want:     "32",
+			wantOK:   true,
+		},
+		"suffix_only_for_files": {
+			lsColors: "*.d=31",
+			name:     "conf.d",
+			mode:     entryModeDir,
+			info:     dir,
+		},
+		"file_fallback": {
+			lsColors: "fi=37:*.go=32",
+			name:     "README",
+			mode:     entryModeFile,
+			info:     file,
+			want:     "37",
+			wantOK:   true,
+		},
+		"hidden": {
+			lsColors: "hi=90:fi=37",
+			name:     ".env",
+			mode:     entryModeFile | entryModeHidden,
+			info:     file,
+			want:     "90",
+			wantOK:   true,
+		},
+		"malformed_rules_ignored": {
+			lsColors: "di:=31::ex=32:fi",
+			name:     "run",
+			mode:     entryModeFile | entryModeExec,
+			info:     &mockFileInfo{mode: 0o755},
+			want:     "32",
+			wantOK:   true,
+		},
+		"empty": {
+			lsColors: "",
+			name:     "src",
+			mode:     entryModeDir,
+			info:     dir,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got, ok := parseLSColors(test.lsColors).lookup(test.name, test.mode, test.info)
+			if got != test.want || ok != test.wantOK {
+				tt.Fatalf("expected %q (%t), got %q (%t)", test.want, test.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestLSColorsLinkTarget(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{"to-src": "src", "broken": "missing"} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Skip("symlinks are not supported")
+		}
+	}
+
+	ec := parseLSColors("ln=target:di=01;34:or=31")
+	if !ec.linkTarget {
+		t.Fatal("expected symlinks to be colored as their targets")
+	}
+	if _, ok := ec.types["ln"]; ok {
+		t.Fatal("expected no color for the ln type")
+	}
+	if sgr, ok := ec.lookupLink(filepath.Join(dir, "to-src")); !ok || sgr != "01;34" {
+		t.Fatalf("expected the directory color, got %q", sgr)
+	}
+	if sgr, ok := ec.lookupLink(filepath.Join(dir, "broken")); !ok || sgr != "31" {
+		t.Fatalf("expected the orphan color, got %q", sgr)
+	}
+
+	// A later color for ln replaces coloring by target.
+	if ec := parseLSColors("ln=target:ln=36"); ec.linkTarget || ec.types["ln"] != "36" {
+		t.Fatalf("expected ln to be colored 36, got %q", ec.types["ln"])
+	}
+}
+
+func TestLSColorsLinkTargetInTree(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "sub/file")
+	if err := os.Symlink("file", filepath.Join(dir, "sub", "link")); err != nil {
+		t.Skip("symlinks are not supported")
+	}
+
+	m := newModel()
+	loadTestTree(t, m, dir)
+	m.modeColor = true
+	m.entryColors = parseLSColors("ln=target:or=31")
+	m.treeIdx = 0
+	m.treeToggleExpand()
+
+	// A symlink in a subdirectory is colored by its own target, which has no color here, and not as
+	// an orphan relative to the root of the tree.
+	for i, node := range m.visibleNodes {
+		if node.entry.Name() != "link" {
+			continue
+		}
+		if line := m.renderTreeNode(node, i, m.displayNameOpts()); strings.Contains(line, string(newColor("31"))) {
+			t.Fatalf("expected the symlink not to be colored as an orphan, got %q", line)
+		}
+		return
+	}
+	t.Fatal("expected the symlink in the expanded tree")
+}
+
+func TestDegradeSGR(t *testing.T) {
+	tests := map[string]struct {
+		sgr     string
+		profile termenv.Profile
+		want    string
+	}{
+		"truecolor_unchanged": {
+			sgr:     "38;2;255;0;0",
+			profile: termenv.TrueColor,
+			want:    "38;2;255;0;0",
+		},
+		"truecolor_to_256": {
+			sgr:     "38;2;255;0;0",
+			profile: termenv.ANSI256,
+			want:    "38;5;196",
+		},
+		"256_unchanged": {
+			sgr:     "01;38;5;196",
+			profile: termenv.ANSI256,
+			want:    "01;38;5;196",
+		},
+		"256_to_ansi": {
+			sgr:     "01;38;5;196",
+			profile: termenv.ANSI,
+			want:    "01;91",
+		},
+		"background_to_ansi": {
+			sgr:     "48;5;21;01",
+			profile: termenv.ANSI,
+			want:    "104;01",
+		},
+		"ansi_unchanged": {
+			sgr:     "01;31",
+			profile: termenv.ANSI,
+			want:    "01;31",
+		},
+		"ascii_keeps_attributes": {
+			sgr:     "01;38;5;196;44",
+			profile: termenv.Ascii,
+			want:    "01",
+		},
+		"malformed_unchanged": {
+			sgr:     "38;5;x",
+			profile: termenv.ANSI,
+			want:    "38;5;x",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			if got := degradeSGR(test.sgr, test.profile); got != test.want {
+				tt.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
+
+	"github.com/dkaslovsky/nav/internal/quote"
 )
 
 // Name of the application.
@@ -18,6 +20,11 @@ const name = "nav"
 var version string
 
 const (
+	flagBind                = "--bind"
+	flagBookmark            = "--bookmark"
+	flagColor               = "--color"
+	flagDirsFirst           = "--dirs-first"
+	flagGit                 = "--git"
 	flagHelp                = "--help"
 	flagHelpShort           = "-h"
 	flagHelpShortCaps       = "-H"
@@ -25,33 +32,102 @@ const (
 	flagVersionShort        = "-v"
 	flagSearch              = "--search"
 	flagSearchShort         = "-s"
+	flagSearchStrategy      = "--search-strategy"
+	flagSearchPaths         = "--search-paths"
+	flagFlat                = "--flat"
 	flagPipe                = "--pipe"
+	flagPreview             = "--preview"
 	flagFollowSymlinks      = "--follow"
 	flagFollowSymlinksShort = "-f"
 	flagHidden              = "--hidden"
+	flagIgnoreCase          = "--ignore-case"
+	flagIndexCache          = "--index-cache"
+	flagJump                = "--jump"
+	flagShowIgnored         = "--show-ignored"
 	flagHiddenShort         = "-a"
 	flagList                = "--list"
 	flagListShort           = "-l"
 	flagNoColor             = "--no-color"
+	flagNoConfig            = "--no-config"
+	flagNoDirsFirst         = "--no-dirs-first"
+	flagNoFlat              = "--no-flat"
+	flagNoFollowSymlinks    = "--no-follow"
+	flagNoGit               = "--no-git"
+	flagNoHidden            = "--no-hidden"
+	flagNoIgnoreCase        = "--no-ignore-case"
+	flagNoIndexCache        = "--no-index-cache"
+	flagNoList              = "--no-list"
+	flagNoPreview           = "--no-preview"
+	flagNoReverse           = "--no-reverse"
+	flagNoSearchPaths       = "--no-search-paths"
+	flagNoShowIgnored       = "--no-show-ignored"
 	flagNoStatusBar         = "--no-status-bar"
 	flagNoTrailing          = "--no-trailing"
+	flagNoTree              = "--no-tree"
+	flagNoWatch             = "--no-watch"
+	flagOutput              = "--output"
+	flagPrint0              = "--print0"
+	flagQuote               = "--quote"
+	flagRebuildIndex        = "--rebuild-index"
 	flagRemapEsc            = "--remap-esc"
+	flagReverse             = "--reverse"
+	flagReverseShort        = "-r"
+	flagSort                = "--sort"
+	flagSortSizeShort       = "-S"
+	flagSortExtensionShort  = "-X"
+	flagStatusBar           = "--status-bar"
+	flagTrailing            = "--trailing"
+	flagTheme               = "--theme"
 	flagTree                = "--tree"
 	flagTreeShort           = "-t"
+	flagWatch               = "--watch"
 )
 
 func main() {
 	var err error
 
+	// Edit bookmarks without starting the application.
+	if len(os.Args) > 1 && os.Args[1] == commandBookmarks {
+		exit(runBookmarksCommand(os.Args[2:], os.Stdout), 0)
+	}
+
 	// Initialize model with defaults.
 	m := newModel()
 
+	// Honor NO_COLOR (https://no-color.org/) as a default that the config file and args can override.
+	if termenv.EnvNoColor() {
+		m.modeColor = false
+	}
+
+	// Set model options from the config file, which args take precedence over.
+	if !hasFlag(os.Args[1:], flagNoConfig) {
+		err = m.loadConfig()
+		if err != nil {
+			exit(err, m.exitCode)
+		}
+	}
+
 	// Set model options from args.
 	err = parseArgs(os.Args[1:], m)
 	if err != nil {
 		exit(err, m.exitCode)
 	}
 
+	// Validate key bindings after all remappings are applied.
+	err = checkKeyConflicts()
+	if err != nil {
+		exit(err, m.exitCode)
+	}
+
+	// Terminal coloring.
+	output := termenv.NewOutput(os.Stderr)
+	profile := output.EnvColorProfile()
+	lipgloss.SetColorProfile(profile)
+	err = m.loadTheme(profile)
+	if err != nil {
+		exit(err, m.exitCode)
+	}
+
 	// Populate the model.
 	if m.modeTree {
 		err, _ = m.listTree()
@@ -65,31 +141,64 @@ func main() {
 		}
 	}
 
-	// Terminal coloring.
-	output := termenv.NewOutput(os.Stderr)
-	lipgloss.SetColorProfile(output.ColorProfile())
+	// The starting directory counts as visited.
+	m.visits = append(m.visits, m.path)
 
 	// Run the app.
 	finalModel, err := tea.NewProgram(m, tea.WithOutput(os.Stderr)).Run()
+	m.stopWatcher()
 	if err != nil {
 		exit(err, m.exitCode)
 	}
 
-	// Write exit string to stdout if set
-	if finalModel, ok := finalModel.(*model); ok && finalModel.exitStr != "" {
-		fmt.Println(finalModel.exitStr)
+	// Write the returned paths to stdout if set
+	if finalModel, ok := finalModel.(*model); ok {
+		// Recording visits is best effort and never prevents returning paths.
+		_ = finalModel.recordFrecency()
+
+		out, err := finalModel.exitOutput()
+		if err != nil {
+			exit(err, m.exitCode)
+		}
+		fmt.Print(out)
 	}
 
 	exit(nil, m.exitCode)
 }
 
+// valueFlags are the flags that take a value.
+var valueFlags = []string{
+	flagOutput,
+	flagQuote,
+	flagSort,
+	flagSearchStrategy,
+	flagBind,
+	flagTheme,
+	flagBookmark,
+	flagRemapEsc,
+	flagJump,
+}
+
 func parseArgs(args []string, m *model) error {
-	var err error
+	var (
+		err     error
+		hasPath bool // A directory argument sets the starting directory.
+		jumped  bool // --jump sets the starting directory.
+	)
 
 	i := 0
 	for i < len(args) {
 		arg := args[i]
 
+		// Flags taking a value accept it as the following arg or in the form --flag=value.
+		if flag, value, found := strings.Cut(arg, "="); found && strings.HasPrefix(flag, "--") {
+			if !hasFlag(valueFlags, flag) {
+				return fmt.Errorf("unexpected value for flag: %s", arg)
+			}
+			args = append(args[:i:i], append([]string{flag, value}, args[i+1:]...)...)
+			arg = flag
+		}
+
 		switch arg {
 		case flagHelp, flagHelpShort, flagHelpShortCaps:
 			usageAndExit()
@@ -97,22 +206,173 @@ func parseArgs(args []string, m *model) error {
 			versionAndExit()
 		case flagHidden, flagHiddenShort:
 			m.modeHidden = true
+		case flagShowIgnored:
+			m.modeIgnored = true
 		case flagList, flagListShort:
 			m.modeList = true
 		case flagSearch, flagSearchShort:
 			m.modeSearch = true
+		case flagSearchPaths:
+			m.modeSearchPaths = true
+		case flagFlat:
+			m.modeSearchFlat = true
 		case flagPipe:
 			m.modeSubshell = true
+		case flagPrint0:
+			m.output = outputPrint0
+		case flagPreview:
+			m.modePreview = true
 		case flagFollowSymlinks, flagFollowSymlinksShort:
 			m.modeFollowSymlink = true
 		case flagNoColor:
 			m.modeColor = false
+		case flagNoConfig:
+			// Handled before parsing args.
 		case flagNoTrailing:
 			m.modeTrailing = false
+		case flagNoGit:
+			m.modeGit = false
+		case flagNoWatch:
+			m.modeWatch = false
+		case flagRebuildIndex:
+			m.rebuildIndex = true
+		case flagNoIndexCache:
+			m.modeIndexCache = false
 		case flagNoStatusBar:
 			m.hideStatusBar = true
 		case flagTree, flagTreeShort:
 			m.modeTree = true
+		case flagNoHidden:
+			m.modeHidden = false
+		case flagNoShowIgnored:
+			m.modeIgnored = false
+		case flagNoList:
+			m.modeList = false
+		case flagNoTree:
+			m.modeTree = false
+		case flagNoFollowSymlinks:
+			m.modeFollowSymlink = false
+		case flagNoPreview:
+			m.modePreview = false
+		case flagNoSearchPaths:
+			m.modeSearchPaths = false
+		case flagNoFlat:
+			m.modeSearchFlat = false
+		case flagColor:
+			m.modeColor = true
+		case flagTrailing:
+			m.modeTrailing = true
+		case flagGit:
+			m.modeGit = true
+		case flagWatch:
+			m.modeWatch = true
+		case flagIndexCache:
+			m.modeIndexCache = true
+		case flagStatusBar:
+			m.hideStatusBar = false
+		case flagSortSizeShort:
+			m.sort.mode = sortModeSize
+		case flagSortExtensionShort:
+			m.sort.mode = sortModeExtension
+		case flagReverse, flagReverseShort:
+			m.sort.reverse = true
+		case flagIgnoreCase:
+			m.sort.ignoreCase = true
+		case flagNoDirsFirst:
+			m.sort.dirsFirst = false
+		case flagNoReverse:
+			m.sort.reverse = false
+		case flagNoIgnoreCase:
+			m.sort.ignoreCase = false
+		case flagDirsFirst:
+			m.sort.dirsFirst = true
+		case flagOutput:
+			if i > len(args)-2 {
+				return fmt.Errorf("%s must be followed by an output format", flagOutput)
+			}
+			m.output, err = parseOutputFormat(args[i+1])
+			if err != nil {
+				return err
+			}
+			i += 2
+			continue
+		case flagQuote:
+			if i > len(args)-2 {
+				return fmt.Errorf("%s must be followed by a quoting style", flagQuote)
+			}
+			m.quote, err = quote.ParseStyle(args[i+1])
+			if err != nil {
+				return err
+			}
+			i += 2
+			continue
+		case flagSearchStrategy:
+			if i > len(args)-2 {
+				return fmt.Errorf("%s must be followed by a search strategy", flagSearchStrategy)
+			}
+			m.searchStrategy, err = parseSearchStrategy(args[i+1])
+			if err != nil {
+				return err
+			}
+			i += 2
+			continue
+		case flagSort:
+			if i > len(args)-2 {
+				return fmt.Errorf("%s must be followed by a sort mode", flagSort)
+			}
+			m.sort.mode, err = parseSortMode(args[i+1])
+			if err != nil {
+				return err
+			}
+			i += 2
+			continue
+		case flagBind:
+			if i > len(args)-2 {
+				return fmt.Errorf("%s must be followed by an action=key value", flagBind)
+			}
+			err := parseKeyBinding(args[i+1])
+			if err != nil {
+				return err
+			}
+			i += 2
+			continue
+		case flagTheme:
+			if i > len(args)-2 {
+				return fmt.Errorf("%s must be followed by a theme name or path", flagTheme)
+			}
+			m.theme = args[i+1]
+			i += 2
+			continue
+		case flagJump:
+			if hasPath {
+				return fmt.Errorf("%s cannot be used with a directory argument", flagJump)
+			}
+			// Keywords end at the next flag.
+			keywords := []string{}
+			for i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				keywords = append(keywords, args[i+1])
+				i++
+			}
+			if len(keywords) == 0 {
+				return fmt.Errorf("%s must be followed by one or more keywords", flagJump)
+			}
+			jumped = true
+			m.path, err = jumpPath(keywords)
+			if err != nil {
+				return err
+			}
+			i++
+			continue
+		case flagBookmark:
+			if i > len(args)-2 {
+				return fmt.Errorf("%s must be followed by a bookmark name", flagBookmark)
+			}
+			m.path, err = bookmarkPath(args[i+1])
+			if err != nil {
+				return err
+			}
+			i += 2
+			continue
 		case flagRemapEsc:
 			if i > len(args)-2 {
 				return fmt.Errorf("%s must be followed by a string value", flagRemapEsc)
@@ -127,6 +387,10 @@ func parseArgs(args []string, m *model) error {
 			if strings.HasPrefix(arg, "-") {
 				return fmt.Errorf("unknown flag: %s", arg)
 			}
+			if jumped {
+				return fmt.Errorf("%s cannot be used with a directory argument", flagJump)
+			}
+			hasPath = true
 			m.path, err = filepath.Abs(arg)
 			if err != nil {
 				return err
@@ -146,6 +410,15 @@ func parseArgs(args []string, m *model) error {
 	return nil
 }
 
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag {
+			return true
+		}
+	}
+	return false
+}
+
 func exit(err error, code int) {
 	if err != nil {
 		fmt.Printf("fatal: %v", err)
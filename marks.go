@@ -1,88 +1,192 @@
 package main
 
-import "errors"
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+)
 
 func (m *model) marked() bool {
 	return m.markedIndex(m.displayIndex())
 }
 
+// markedIndex returns true if the entry at the display index of the grid is in the basket.
 func (m *model) markedIndex(dispIdx int) bool {
-	_, marked := m.marks[dispIdx]
+	if len(m.basket) == 0 {
+		return false
+	}
+	ent, err := m.displayEntry(dispIdx)
+	if err != nil {
+		return false
+	}
+	_, marked := m.basket[filepath.Join(m.path, ent.Name())]
 	return marked
 }
 
-func (m *model) reloadMarks() error {
-	if len(m.marks) == 0 {
-		return nil
-	}
-	newMarks := make(map[int]int)
+// displayEntry returns the entry at the display index of the grid.
+func (m *model) displayEntry(dispIdx int) (*entry, error) {
 	cache, ok := m.pathCache[m.path]
 	if !ok || !cache.hasIndexes() {
-		return errors.New("failed to load page cache indexes")
+		return nil, errors.New("failed to load page cache indexes")
 	}
-	for _, entryIdx := range m.marks {
-		if newDisplayIdx, ok := cache.lookupDisplayIndex(entryIdx); ok {
-			newMarks[newDisplayIdx] = entryIdx
-		}
+	entryIdx, ok := cache.lookupEntryIndex(dispIdx)
+	if !ok || entryIdx >= len(m.entries) {
+		return nil, errors.New("failed to find entry index")
 	}
-	m.marks = newMarks
-	return nil
+	return m.entries[entryIdx], nil
 }
 
+// toggleMark adds the entry under the cursor to the basket or removes it.
 func (m *model) toggleMark() error {
-	idx := m.displayIndex()
-	if m.markedIndex(idx) {
-		delete(m.marks, idx)
-		m.modeMarks = len(m.marks) != 0
-		return nil
-	}
-
-	cache, ok := m.pathCache[m.path]
-	if !ok || !cache.hasIndexes() {
-		return errors.New("failed to load page cache indexes")
+	ent, err := m.displayEntry(m.displayIndex())
+	if err != nil {
+		return err
 	}
-	entryIdx, ok := cache.lookupEntryIndex(idx)
-	if !ok {
-		return errors.New("failed to find entry index")
+	path := filepath.Join(m.path, ent.Name())
+	if _, marked := m.basket[path]; marked {
+		delete(m.basket, path)
+	} else {
+		m.basket[path] = ent
 	}
-	m.marks[idx] = entryIdx
-	m.modeMarks = true
+	m.modeMarks = len(m.basket) != 0
 	return nil
 }
 
+// toggleMarkAll adds all displayed entries to the basket or, if they are all in it, removes them.
+// Entries of other directories are kept.
 func (m *model) toggleMarkAll() error {
+	if m.modeTree {
+		m.toggleTreeMarkAll()
+		return nil
+	}
+
 	// Check if all displayed entries are marked to determine toggle behavior.
 	allMarked := true
 	for i := 0; i < m.displayed; i++ {
-		if _, marked := m.marks[i]; !marked {
+		if !m.markedIndex(i) {
 			allMarked = false
 			break
 		}
 	}
 
 	if allMarked {
-		m.clearMarks()
+		for i := 0; i < m.displayed; i++ {
+			if ent, err := m.displayEntry(i); err == nil {
+				delete(m.basket, filepath.Join(m.path, ent.Name()))
+			}
+		}
+		m.modeMarks = len(m.basket) != 0
 		return nil
 	}
 	return m.markAll()
 }
 
+// markAll adds all displayed entries to the basket.
 func (m *model) markAll() error {
-	m.marks = make(map[int]int)
+	if m.modeTree {
+		m.toggleTreeMarkAll()
+		return nil
+	}
 	cache, ok := m.pathCache[m.path]
 	if !ok || !cache.hasIndexes() {
 		return errors.New("failed to load page cache indexes")
 	}
 	for i := 0; i < m.displayed; i++ {
-		if entryIdx, ok := cache.lookupEntryIndex(i); ok {
-			m.marks[i] = entryIdx
+		if ent, err := m.displayEntry(i); err == nil {
+			m.basket[filepath.Join(m.path, ent.Name())] = ent
 		}
 	}
-	m.modeMarks = len(m.marks) != 0
+	m.modeMarks = len(m.basket) != 0
 	return nil
 }
 
-func (m *model) clearMarks() {
-	m.marks = make(map[int]int)
-	m.modeMarks = false
+// pruneMarks removes the marks of entries that no longer exist, such as those moved, renamed, or
+// trashed by file operations, keeping the marks of the other entries.
+func (m *model) pruneMarks() {
+	for _, marks := range []map[string]*entry{m.basket, m.treeMarks} {
+		for path := range marks {
+			if _, err := os.Lstat(path); err != nil {
+				delete(marks, path)
+			}
+		}
+	}
+	m.modeMarks = len(m.markSet()) != 0
+}
+
+// toggleTreeMark toggles the mark on the tree node under the cursor.
+func (m *model) toggleTreeMark() {
+	node := m.selectedTreeNode()
+	if node == nil || node.entry == nil {
+		return
+	}
+	if _, marked := m.treeMarks[node.fullPath]; marked {
+		delete(m.treeMarks, node.fullPath)
+	} else {
+		m.treeMarks[node.fullPath] = node.entry
+	}
+	m.modeMarks = len(m.treeMarks) != 0
+}
+
+// toggleTreeMarkSubtree toggles the mark on the tree node under the cursor and on all of its
+// descendants, loading directories that have not been expanded.
+func (m *model) toggleTreeMarkSubtree() {
+	node := m.selectedTreeNode()
+	if node == nil || node.entry == nil {
+		return
+	}
+	_, marked := m.treeMarks[node.fullPath]
+	for _, n := range node.collectAllDescendants(m.nodeFilter(), m.sort) {
+		if n.entry == nil {
+			continue
+		}
+		if marked {
+			delete(m.treeMarks, n.fullPath)
+		} else {
+			m.treeMarks[n.fullPath] = n.entry
+		}
+	}
+	m.modeMarks = len(m.treeMarks) != 0
+}
+
+// toggleTreeMarkAll marks all visible tree nodes or, if they are all marked, unmarks them. Marks on
+// nodes that are not visible are kept.
+func (m *model) toggleTreeMarkAll() {
+	allMarked := true
+	for i := range m.visibleNodes {
+		if !m.markedTreeNode(i) {
+			allMarked = false
+			break
+		}
+	}
+	for _, node := range m.visibleNodes {
+		if node.entry == nil {
+			continue
+		}
+		if allMarked {
+			delete(m.treeMarks, node.fullPath)
+		} else {
+			m.treeMarks[node.fullPath] = node.entry
+		}
+	}
+	m.modeMarks = len(m.treeMarks) != 0
+}
+
+// markSet returns the marks of the current view mode: the marked tree nodes or the basket.
+func (m *model) markSet() map[string]*entry {
+	if m.modeTree {
+		return m.treeMarks
+	}
+	return m.basket
+}
+
+// markedPaths returns the sorted paths of the marks of the current view mode.
+func (m *model) markedPaths() []string {
+	marks := m.markSet()
+	paths := make([]string, 0, len(marks))
+	for path := range marks {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTreeMarks(t *testing.T) {
+	m, dir := newTestTree(t, "a/x", "a/y/z", "b")
+	a, ax, ay, ayz, b := filepath.Join(dir, "a"), filepath.Join(dir, "a", "x"), filepath.Join(dir, "a", "y"),
+		filepath.Join(dir, "a", "y", "z"), filepath.Join(dir, "b")
+
+	// Marks stay on the same entry when the rows above it change.
+	m.treeIdx = 1
+	m.toggleTreeMark()
+	m.treeIdx = 0
+	m.treeToggleExpand()
+	if !m.markedTreeNode(3) || m.markedTreeNode(1) {
+		t.Fatalf("expected b to stay marked after expanding a, got marks %v", m.treeMarks)
+	}
+	m.treeToggleExpand()
+	if !m.markedTreeNode(1) {
+		t.Fatalf("expected b to stay marked after collapsing a, got marks %v", m.treeMarks)
+	}
+
+	// Marking a subtree marks every descendant, including those in collapsed directories.
+	m.treeIdx = 0
+	m.toggleTreeMarkSubtree()
+	paths, err := m.selectedPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{a, ax, ay, ayz, b}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected selected paths %v, got %v", want, paths)
+	}
+	m.toggleTreeMarkSubtree()
+	if want := []string{b}; !reflect.DeepEqual(m.markedPaths(), want) {
+		t.Fatalf("expected marked paths %v, got %v", want, m.markedPaths())
+	}
+
+	// Marks survive a search filter that hides them.
+	m.search = "x"
+	m.rebuildVisibleNodes()
+	for i := range m.visibleNodes {
+		if m.markedTreeNode(i) {
+			t.Fatalf("unexpected marked node %s in the filtered tree", m.visibleNodes[i].fullPath)
+		}
+	}
+	m.search = ""
+	m.rebuildVisibleNodes()
+	if want := []string{b}; !reflect.DeepEqual(m.markedPaths(), want) || !m.modeMarks {
+		t.Fatalf("expected marked paths %v, got %v", want, m.markedPaths())
+	}
+}
+
+func TestBasket(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "a/x", "a/y", "b")
+	a, ax, ay, b := filepath.Join(dir, "a"), filepath.Join(dir, "a", "x"), filepath.Join(dir, "a", "y"),
+		filepath.Join(dir, "b")
+
+	m := newModel()
+	m.path = dir
+	if err := m.list(); err != nil {
+		t.Fatal(err)
+	}
+	m.normalView()
+
+	// Marks are kept when changing directory.
+	m.setCursor(newPositionFromIndex(1, m.rows))
+	if err := m.toggleMark(); err != nil {
+		t.Fatal(err)
+	}
+	m.jumpTo(a)
+	m.normalView()
+	if err := m.markAll(); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := m.selectedPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{ax, ay, b}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected selected paths %v, got %v", want, paths)
+	}
+
+	// Toggling all entries only unmarks the entries of the current directory.
+	if err := m.toggleMarkAll(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{b}; !reflect.DeepEqual(m.markedPaths(), want) {
+		t.Fatalf("expected marked paths %v, got %v", want, m.markedPaths())
+	}
+
+	// Refreshing after file operations only unmarks the entries that no longer exist.
+	if err := m.markAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(ay); err != nil {
+		t.Fatal(err)
+	}
+	m.refresh("")
+	m.normalView()
+	if want := []string{ax, b}; !reflect.DeepEqual(m.markedPaths(), want) {
+		t.Fatalf("expected marked paths %v, got %v", want, m.markedPaths())
+	}
+	if err := m.toggleMarkAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The basket view removes entries.
+	m.openBasket()
+	if len(m.basketView.paths) != 1 {
+		t.Fatalf("expected 1 entry in the basket view, got %v", m.basketView.paths)
+	}
+	m.removeFromBasket()
+	if len(m.basket) != 0 || m.modeMarks || len(m.basketView.paths) != 0 {
+		t.Fatalf("expected an empty basket, got %v", m.basket)
+	}
+	m.closeBasket()
+}
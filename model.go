@@ -10,7 +10,12 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/sahilm/fuzzy"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/dkaslovsky/nav/internal/gitignore"
+	"github.com/dkaslovsky/nav/internal/quote"
+	"github.com/dkaslovsky/nav/internal/watch"
 )
 
 var fileSeparator = string(filepath.Separator)
@@ -25,60 +30,79 @@ type searchIndexBatchMsg struct {
 // fuzzySearchResultMsg delivers fuzzy search results from background worker
 type fuzzySearchResultMsg struct {
 	query      string        // Query this result is for (detect stale)
-	matches    []fuzzy.Match // Raw fuzzy matches with scores
+	matches    []searchMatch // Raw matches with scores
 	generation int64         // generation counter to detect stale messages
 }
 
 type model struct {
-	path      string
-	prevPath  string
-	entries   []*entry
-	displayed int
-	exitCode  int
-	exitStr   string
-	error     error
-	errorStr  string
-	esc       *remappedEscKey
-	search    string
-	pathCache map[string]*cacheItem // Map path to cached state.
-	marks     map[int]int           // Map display index to entry index for marked entries.
-
-	c       int // Cursor column position.
-	r       int // Cursor row position.
-	columns int // Displayed columns.
-	rows    int // Displayed columns.
-	width   int // Terminal width.
-	height  int // Terminal height.
+	*tab // State of the current tab.
+
+	tabs   []*tab // Open tabs, including the current tab.
+	tabIdx int    // Position of the current tab.
+
+	visits   []string // Directories visited during the session, recorded in the frecency database on exit.
+	exitCode int
+	exit     *exitPaths
+	lastKey  string // Most recent key pressed, reported by the JSON output format.
+	error    error
+	errorStr string
+	esc      *remappedEscKey
+
+	width        int // Width available to the views of a pane.
+	height       int // Height available to the views below the tab bar.
+	screenWidth  int // Terminal width.
+	screenHeight int // Terminal height.
 
 	modeColor         bool
 	modeError         bool
 	modeExit          bool
 	modeFollowSymlink bool
+	modeGit           bool
+	modeWatch         bool
 	modeHelp          bool
 	modeHidden        bool
+	modeIgnored       bool
 	modeList          bool
-	modeMarks         bool
-	modeSearch        bool
+	modePreview       bool
+	modePrompt        bool
+	modeSearchFlat    bool
+	modeSearchPaths   bool
 	modeSubshell      bool
 	modeTrailing      bool
-	modeTree          bool
+	modeTrash         bool
+	modeBookmarks     bool
+	modeFrecency      bool
+	modeHistory       bool
+	modeBasket        bool
 
 	hideStatusBar bool
 
-	// Tree mode fields
-	treeRoot     *treeNode
-	visibleNodes []*treeNode
-	treeIdx      int
-	scrollOffset int
-	// treeLastChild maps parent directory path to the name of the last selected child.
-	// Used to restore cursor position when re-expanding a previously collapsed directory.
-	// Path-based (not pointer-based) so it survives tree node recreation during navigation.
-	treeLastChild map[string]string
-	// treeSearchStartNode is the node at cursor position when search mode is entered.
-	// Search will be scoped to this node's subtree (or its parent's subtree if it's a file).
-	treeSearchStartNode *treeNode
-	// searchMatchNodes stores the actual fuzzy match results (not ancestors) for returning on Enter
-	searchMatchNodes []*treeNode
+	sort sortOrder
+
+	searchStrategy searchStrategy // Matching of the search query in normal mode
+	output         outputFormat
+	quote          quote.Style // Quoting of returned paths in the shell output format
+
+	preview     *preview // Content of the preview pane for the entry under the cursor
+	prompt      *prompt  // Input prompt for file operations
+	transferDir string   // Destination of the last copy or move, prefilled in the next one
+	git         gitState // Status of the git repository containing the current directory
+	trash       *trashBrowser
+	bookmarks   *bookmarkPicker
+	frecency    *frecencyPicker
+	historyView *historyPicker
+	basketView  *basketPicker
+
+	// Theme fields
+	theme        string       // Name or path of the theme
+	modeLSColors bool         // Use LS_COLORS for entry colors when it is set
+	entryColors  *entryColors // Resolved entry coloring rules
+
+	watcher      *watch.Watcher // Watcher of the listed directories, nil if watching is off
+	watchPending []string       // Changed tree directories waiting for the index loader to finish
+
+	modeIndexCache bool // Cache the tree view search index on disk
+	rebuildIndex   bool // Ignore the cached search indexes, rebuilding them
 
 	// Search index streaming fields
 	searchIndexNodes     []*treeNode      // Accumulated nodes for fuzzy matching
@@ -86,8 +110,10 @@ type model struct {
 	searchIndexLoading   bool             // True while background loader is running
 	searchIndexChan      chan []*treeNode // Channel for receiving batches from goroutine
 	searchIndexCancel    func()           // Cancel function to stop the background goroutine
+	searchIndexDone      chan struct{}    // Closed when the background goroutine exits
 	searchIndexRoot      *treeNode        // Root node being indexed (for reuse detection)
-	searchPendingMatches []fuzzy.Match    // Accumulated matches during indexing (for incremental matching)
+	searchIndexCache     *indexCache      // On-disk cache of the index of the root, nil if caching is off
+	searchPendingMatches []searchMatch    // Accumulated matches during indexing (for incremental matching)
 
 	// Background fuzzy search worker fields
 	searchQueryChan        chan string               // Send queries to background worker
@@ -101,37 +127,56 @@ type model struct {
 }
 
 func newModel() *model {
+	t := newTab()
 	return &model{
-		width:     80,
-		height:    60,
-		esc:       defaultEscRemapKey(),
-		pathCache: make(map[string]*cacheItem),
-		marks:     make(map[int]int),
+		tab:  t,
+		tabs: []*tab{t},
+
+		width:        80,
+		height:       60,
+		screenWidth:  80,
+		screenHeight: 60,
+		esc:          defaultEscRemapKey(),
 
 		modeColor:         true,
 		modeError:         false,
 		modeExit:          false,
 		modeFollowSymlink: false,
+		modeGit:           true,
+		modeWatch:         true,
 		modeHelp:          false,
 		modeHidden:        false,
+		modeIgnored:       false,
 		modeList:          false,
-		modeMarks:         false,
-		modeSearch:        false,
+		modePreview:       false,
+		modePrompt:        false,
+		modeSearchFlat:    false,
+		modeSearchPaths:   false,
 		modeSubshell:      false,
 		modeTrailing:      true,
-		modeTree:          false,
+		modeTrash:         false,
+		modeBookmarks:     false,
+		modeFrecency:      false,
+		modeHistory:       false,
+		modeBasket:        false,
 
 		hideStatusBar: false,
 
-		treeIdx:             0,
-		scrollOffset:        0,
-		treeLastChild:       make(map[string]string),
-		treeSearchStartNode: nil,
+		sort:           defaultSortOrder(),
+		searchStrategy: searchPrefix,
+		output:         outputShell,
+		quote:          quote.Default,
+
+		theme:        themeDark,
+		modeLSColors: true,
+		entryColors:  defaultEntryColors(),
+
+		modeIndexCache: true,
 	}
 }
 
 func (m *model) normalMode() bool {
-	return !(m.modeSearch || m.modeHelp)
+	return !(m.modeSearch || m.modeHelp || m.modePrompt || m.modeTrash || m.modeBookmarks || m.modeFrecency || m.modeHistory || m.modeBasket)
 }
 
 func (m *model) list() error {
@@ -148,11 +193,76 @@ func (m *model) list() error {
 		}
 		m.entries = append(m.entries, ent)
 	}
-	sortEntries(m.entries)
+	sortEntries(m.entries, m.sort)
+	m.syncWatches()
 
 	return nil
 }
 
+// entryIndex returns the index of the named entry.
+func (m *model) entryIndex(name string) (int, bool) {
+	if name == "" {
+		return 0, false
+	}
+	for i, ent := range m.entries {
+		if ent.Name() == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// resort orders the entries, or the loaded tree, using the current sort order while keeping the
+// cursor and marks on the same entries.
+func (m *model) resort() tea.Cmd {
+	if m.modeTree {
+		// The index loader walks the tree and sorts the directories it loads, so it is stopped
+		// while the tree is sorted and restarted with the new order if it had not finished.
+		loading := m.searchIndexLoading
+		m.stopSearchIndexLoader()
+
+		// Tree marks are keyed by path, so only the cursor needs to follow its node.
+		selectedNode := m.selectedTreeNode()
+		if m.treeRoot != nil {
+			m.treeRoot.refreshInfos(m.sort)
+			m.treeRoot.sortChildren(m.sort)
+		}
+		m.rebuildVisibleNodes()
+		for i, node := range m.visibleNodes {
+			if node == selectedNode {
+				m.treeIdx = i
+			}
+		}
+		m.adjustScrollOffset()
+
+		if loading {
+			return m.startSearchIndexLoader(m.treeRoot)
+		}
+		return nil
+	}
+
+	// The cursor is restored by entry name from the cache when the view is rendered, and marks
+	// are keyed by path.
+	m.saveCursor()
+	sortEntries(m.entries, m.sort)
+	return nil
+}
+
+// reindexTree rebuilds the tree and restarts the index loader, after the node filter changes or
+// when more entries are added than are indexed at once.
+func (m *model) reindexTree() tea.Cmd {
+	m.stopSearchIndexLoader()
+	if m.search == "" {
+		m.rebuildVisibleNodes()
+		return m.startSearchIndexLoader(m.treeRoot)
+	}
+
+	// Search results are matched again as the restarted loader indexes the tree.
+	cmd := m.startSearchIndexLoader(m.treeRoot)
+	m.rebuildVisibleNodesFromMatches(nil)
+	return cmd
+}
+
 func (m *model) selected() (*entry, error) {
 	cache, ok := m.pathCache[m.path]
 	if !ok {
@@ -181,8 +291,9 @@ func (m *model) location() string {
 
 func (m *model) displayNameOpts() []displayNameOption {
 	opts := []displayNameOption{}
-	if m.modeColor {
-		opts = append(opts, displayNameWithColor())
+	// Entries of the tree are in different directories, so they are colored as they are rendered.
+	if m.modeColor && !m.modeTree {
+		opts = append(opts, displayNameWithColor(m.entryColors, m.path))
 	}
 	if m.modeFollowSymlink {
 		opts = append(opts, displayNameWithFollowSymlink(m.path))
@@ -193,19 +304,50 @@ func (m *model) displayNameOpts() []displayNameOption {
 	if m.modeTrailing {
 		opts = append(opts, displayNameWithTrailing())
 	}
+	if m.modeGit && m.git.status != nil && !m.modeTree {
+		opts = append(opts, displayNameWithGitStatus(m.git.status, m.path))
+	}
 	return opts
 }
 
+// highlightStyle returns how characters matched by a search are rendered. Brackets are used when
+// the terminal does not support styling, such as when NO_COLOR is set.
+func (m *model) highlightStyle() highlightStyle {
+	switch {
+	case lipgloss.ColorProfile() == termenv.Ascii:
+		return highlightBrackets
+	case !m.modeColor:
+		return highlightUnderline
+	}
+	return highlightColor
+}
+
+// nodeFilter returns the filter selecting the tree nodes that are shown and indexed.
+func (m *model) nodeFilter() nodeFilter {
+	return nodeFilter{hidden: m.modeHidden, ignored: m.modeIgnored}
+}
+
 func (m *model) displayIndex() int {
 	return index(m.c, m.r, m.rows)
 }
 
 func (m *model) setPath(path string) {
+	if len(m.history.paths) == 0 && m.path != "" {
+		m.history.push(m.path)
+	}
+	m.prevHistory = m.history
+	m.history.push(path)
+
 	m.prevPath = m.path
 	m.path = path
+	m.visits = append(m.visits, path)
 }
 
 func (m *model) restorePath() {
+	if n := len(m.visits); n > 0 && m.visits[n-1] == m.path {
+		m.visits = m.visits[:n-1]
+	}
+	m.history = m.prevHistory
 	if m.prevPath != "" {
 		m.path = m.prevPath
 		m.prevPath = ""
@@ -224,13 +366,9 @@ func (m *model) clearError() {
 	m.error = nil
 }
 
-func (m *model) setExit(exitStr string) {
-	m.setExitWithCode(exitStr, 0)
-}
-
-func (m *model) setExitWithCode(exitStr string, exitCode int) {
+func (m *model) setExitWithCode(exitCode int) {
 	m.modeExit = true
-	m.exitStr = exitStr
+	m.exit = nil
 	m.exitCode = exitCode
 }
 
@@ -239,12 +377,14 @@ func (m *model) clearSearch() {
 	m.search = ""
 	m.treeSearchStartNode = nil
 	m.searchMatchNodes = nil
+	m.searchHighlights = nil
 	m.searchPendingMatches = nil
 	m.stopSearchWorker()
 	// Note: searchIndexNodes/Names are kept for reuse
 }
 
-// stopSearchIndexLoader cancels the background indexing goroutine and cleans up
+// stopSearchIndexLoader cancels the background indexing goroutine and waits for it to exit, so
+// that the tree it was walking can be modified once it returns.
 func (m *model) stopSearchIndexLoader() {
 	if m.searchIndexCancel != nil {
 		m.searchIndexCancel()
@@ -259,6 +399,10 @@ func (m *model) stopSearchIndexLoader() {
 			}
 		}()
 	}
+	if m.searchIndexDone != nil {
+		<-m.searchIndexDone
+		m.searchIndexDone = nil
+	}
 	m.searchIndexLoading = false
 }
 
@@ -282,10 +426,20 @@ func (m *model) startSearchIndexLoader(root *treeNode) tea.Cmd {
 	ctx, cancel := context.WithCancel(context.Background())
 	m.searchIndexCancel = cancel
 	m.searchIndexChan = make(chan []*treeNode, 10)
+	m.searchIndexDone = make(chan struct{})
+
+	m.searchIndexCache = m.newIndexCache(root)
 
+	// Capture the channels so that a restarted loader does not close the channels of its successor.
+	ch, done, filter, order, cache := m.searchIndexChan, m.searchIndexDone, m.nodeFilter(), m.sort, m.searchIndexCache
 	go func() {
-		defer close(m.searchIndexChan)
-		streamDFS(ctx, root, m.modeHidden, m.searchIndexChan)
+		defer close(done)
+		defer close(ch)
+		cache.load(ctx)
+		streamDFS(ctx, root, filter, order, cache, ch)
+		if cache != nil {
+			cache.dirs = nil
+		}
 	}()
 
 	return m.pollSearchIndexCmd()
@@ -354,6 +508,8 @@ func (m *model) startSearchWorker() tea.Cmd {
 
 	m.searchWorkerGeneration++ // Increment generation to invalidate old messages
 	gen := m.searchWorkerGeneration
+	root := m.treeSearchRootPath() // Captured for matching relative paths
+	matchPaths := m.modeSearchPaths
 
 	ctx, cancel := context.WithCancel(context.Background())
 	m.searchWorkerCancel = cancel
@@ -383,12 +539,14 @@ func (m *model) startSearchWorker() tea.Cmd {
 					continue
 				}
 
-				// Create a snapshot of names up to current length
+				// Create a snapshot of names and nodes up to current length
 				indexNames := make([]string, indexLen)
 				copy(indexNames, m.searchIndexNames[:indexLen])
+				indexNodes := make([]*treeNode, indexLen)
+				copy(indexNodes, m.searchIndexNodes[:indexLen])
 
-				// Run fuzzy search in background
-				matches := fuzzy.Find(query, indexNames)
+				// Run search in background
+				matches := findTreeMatches(query, root, indexNames, indexNodes, matchPaths)
 
 				// Send result (non-blocking)
 				select {
@@ -420,12 +578,44 @@ func (m *model) pollSearchResultCmd() tea.Cmd {
 	}
 }
 
+// findTreeMatches matches a search query against index names, matching terms containing "/"
+// against the paths of the index nodes relative to root. All terms are matched against the paths
+// if matchPaths is set. Tree view mode always matches fuzzily, ignoring the search strategy.
+//
+// The matched characters are offsets in the paths relative to root whether or not the paths are
+// matched, so that each presentation of the results highlights them without knowing how they were
+// matched.
+func findTreeMatches(query string, root string, names []string, nodes []*treeNode, matchPaths bool) []searchMatch {
+	paths := func() []string {
+		paths := make([]string, len(nodes))
+		for i, node := range nodes {
+			paths[i] = searchPath(root, node.fullPath)
+		}
+		return paths
+	}
+	if matchPaths {
+		return matchSearchQueryPaths(parseSearchQuery(query), searchFuzzy, paths())
+	}
+
+	matches := matchSearchQuery(parseSearchQuery(query), searchFuzzy, names, paths)
+	for i, match := range matches {
+		path, name := searchPath(root, nodes[match.index].fullPath), names[match.index]
+		if !strings.HasSuffix(path, name) {
+			// The relative path of the root itself does not end with its name.
+			matches[i].matched = nil
+			continue
+		}
+		matches[i].matched = pathOffsets(match.matched, len(path)-len(name))
+	}
+	return matches
+}
+
 // mergeMatchesByScore merges two sorted match slices maintaining score order
-func mergeMatchesByScore(a, b []fuzzy.Match) []fuzzy.Match {
-	result := make([]fuzzy.Match, 0, len(a)+len(b))
+func mergeMatchesByScore(a, b []searchMatch) []searchMatch {
+	result := make([]searchMatch, 0, len(a)+len(b))
 	i, j := 0, 0
 	for i < len(a) && j < len(b) {
-		if a[i].Score >= b[j].Score {
+		if a[i].score >= b[j].score {
 			result = append(result, a[i])
 			i++
 		} else {
@@ -438,12 +628,13 @@ func mergeMatchesByScore(a, b []fuzzy.Match) []fuzzy.Match {
 	return result
 }
 
-// rebuildVisibleNodesFromMatches builds visible nodes from fuzzy match results
-func (m *model) rebuildVisibleNodesFromMatches(fuzzyMatches []fuzzy.Match) {
-	if len(fuzzyMatches) == 0 {
+// rebuildVisibleNodesFromMatches builds visible nodes from search match results
+func (m *model) rebuildVisibleNodesFromMatches(matches []searchMatch) {
+	if len(matches) == 0 {
 		m.visibleNodes = nil
 		m.displayed = 0
 		m.searchMatchNodes = nil
+		m.searchHighlights = nil
 		if m.treeIdx >= len(m.visibleNodes) {
 			m.treeIdx = max(0, len(m.visibleNodes)-1)
 		}
@@ -451,29 +642,29 @@ func (m *model) rebuildVisibleNodesFromMatches(fuzzyMatches []fuzzy.Match) {
 	}
 
 	// Determine search root
-	searchRoot := m.treeSearchStartNode
-	if searchRoot == nil {
-		searchRoot = m.treeRoot
-	}
+	searchRoot := m.treeSearchRoot()
 
 	// Filter matches to only include nodes under the search root
 	// This is needed because the index may contain nodes from parent directories
 	searchRootPrefix := searchRoot.fullPath + string(filepath.Separator)
-	matchingNodes := make([]*treeNode, 0, len(fuzzyMatches))
-	for _, match := range fuzzyMatches {
-		if match.Index < len(m.searchIndexNodes) {
-			node := m.searchIndexNodes[match.Index]
+	matchingNodes := make([]*treeNode, 0, len(matches))
+	highlights := make(map[*treeNode][]int, len(matches))
+	for _, match := range matches {
+		if match.index < len(m.searchIndexNodes) {
+			node := m.searchIndexNodes[match.index]
 			// Only include if node is under search root (or is the search root itself)
 			if node.fullPath == searchRoot.fullPath ||
 				strings.HasPrefix(node.fullPath, searchRootPrefix) {
 				matchingNodes = append(matchingNodes, node)
+				highlights[node] = match.matched
 			}
 		}
 	}
 
 	m.searchMatchNodes = matchingNodes
+	m.searchHighlights = highlights
 
-	m.visibleNodes = buildFilteredTree(searchRoot, matchingNodes, m.modeHidden)
+	m.visibleNodes = m.searchResultNodes(searchRoot, matchingNodes)
 	m.displayed = len(m.visibleNodes)
 
 	if m.treeIdx >= len(m.visibleNodes) {
@@ -481,6 +672,20 @@ func (m *model) rebuildVisibleNodesFromMatches(fuzzyMatches []fuzzy.Match) {
 	}
 }
 
+// updateTreeSearch filters visible nodes for a changed search query, dispatching to the background
+// worker if active and otherwise rebuilding synchronously
+func (m *model) updateTreeSearch() tea.Cmd {
+	if m.searchQueryChan != nil {
+		select {
+		case m.searchQueryChan <- m.search:
+		default:
+		}
+		return m.pollSearchResultCmd()
+	}
+	m.rebuildVisibleNodes()
+	return nil
+}
+
 // rebuildVisibleNodesFromIndex filters visible nodes using the cached search index
 func (m *model) rebuildVisibleNodesFromIndex() {
 	if len(m.searchIndexNodes) == 0 || m.search == "" {
@@ -492,9 +697,10 @@ func (m *model) rebuildVisibleNodesFromIndex() {
 		return
 	}
 
-	// Run fuzzy matching on accumulated index
-	fuzzyMatches := fuzzy.Find(m.search, m.searchIndexNames)
-	m.rebuildVisibleNodesFromMatches(fuzzyMatches)
+	// Run matching on accumulated index
+	root := m.treeSearchRootPath()
+	matches := findTreeMatches(m.search, root, m.searchIndexNames, m.searchIndexNodes, m.modeSearchPaths)
+	m.rebuildVisibleNodesFromMatches(matches)
 }
 
 // formatAbbreviatedCount formats a count as abbreviated (e.g., 5132 -> "5K")
@@ -560,20 +766,31 @@ func index(c int, r int, rows int) int {
 // listTree builds tree structure from current path
 // Returns error and a command to start background indexing
 func (m *model) listTree() (error, tea.Cmd) {
+	if err := m.loadTree(); err != nil {
+		return err, nil
+	}
+
+	// Start background indexing of the tree root
+	// This will be used for fast search when search mode is entered
+	return nil, m.startSearchIndexLoader(m.treeRoot)
+}
+
+// loadTree builds the tree structure from the current path without indexing it.
+func (m *model) loadTree() error {
 	files, err := os.ReadDir(m.path)
 	if err != nil {
-		return err, nil
+		return err
 	}
 
 	entries := make([]*entry, 0, len(files))
 	for _, f := range files {
 		ent, err := newEntry(f)
 		if err != nil {
-			return err, nil
+			return err
 		}
 		entries = append(entries, ent)
 	}
-	sortEntries(entries)
+	sortEntries(entries, m.sort)
 
 	// Create virtual root node (current directory contents are roots)
 	m.treeRoot = &treeNode{
@@ -582,17 +799,18 @@ func (m *model) listTree() (error, tea.Cmd) {
 		expanded: true,
 		loaded:   true,
 	}
+	if root, ok := gitignore.FindRoot(m.path); ok {
+		m.treeRoot.ignore = gitignore.New(root)
+	}
 
+	ignore := m.treeRoot.childIgnore(entries)
 	for _, ent := range entries {
-		m.treeRoot.children = append(m.treeRoot.children,
-			newTreeNode(ent, m.treeRoot, m.path))
+		m.treeRoot.children = append(m.treeRoot.children, m.treeRoot.newChild(ent, ignore))
 	}
 
 	m.rebuildVisibleNodes()
-
-	// Start background indexing of the tree root
-	// This will be used for fast search when search mode is entered
-	return nil, m.startSearchIndexLoader(m.treeRoot)
+	m.syncWatches()
+	return nil
 }
 
 // rebuildVisibleNodes flattens expanded tree into visible nodes list
@@ -605,7 +823,7 @@ func (m *model) rebuildVisibleNodes() {
 	m.visibleNodes = nil
 	if m.treeRoot != nil {
 		for _, child := range m.treeRoot.children {
-			child.flattenInto(&m.visibleNodes, m.modeHidden)
+			child.flattenInto(&m.visibleNodes, m.nodeFilter())
 		}
 	}
 	m.displayed = len(m.visibleNodes)
@@ -635,10 +853,7 @@ func (m *model) rebuildVisibleNodesWithSearch() {
 	}
 
 	// Fallback: collect nodes on-demand (for backward compatibility or if indexing hasn't started)
-	searchRoot := m.treeSearchStartNode
-	if searchRoot == nil {
-		searchRoot = m.treeRoot
-	}
+	searchRoot := m.treeSearchRoot()
 
 	allNodes := make([]*treeNode, 0)
 	if searchRoot == nil {
@@ -650,13 +865,13 @@ func (m *model) rebuildVisibleNodesWithSearch() {
 		if searchRoot.children != nil {
 			for _, child := range searchRoot.children {
 				if child != nil {
-					descendants := child.collectAllDescendants(m.modeHidden)
+					descendants := child.collectAllDescendants(m.nodeFilter(), m.sort)
 					allNodes = append(allNodes, descendants...)
 				}
 			}
 		}
 	} else {
-		descendants := searchRoot.collectAllDescendants(m.modeHidden)
+		descendants := searchRoot.collectAllDescendants(m.nodeFilter(), m.sort)
 		allNodes = append(allNodes, descendants...)
 	}
 
@@ -675,22 +890,25 @@ func (m *model) rebuildVisibleNodesWithSearch() {
 		}
 	}
 
-	fuzzyMatches := fuzzy.Find(m.search, nodeNames)
-	if len(fuzzyMatches) == 0 {
+	matches := findTreeMatches(m.search, searchRoot.fullPath, nodeNames, allNodes, m.modeSearchPaths)
+	if len(matches) == 0 {
 		m.displayed = 0
 		m.treeIdx = 0
 		return
 	}
 
-	matchingNodes := make([]*treeNode, 0, len(fuzzyMatches))
-	for _, match := range fuzzyMatches {
-		if match.Index < len(allNodes) {
-			matchingNodes = append(matchingNodes, allNodes[match.Index])
+	matchingNodes := make([]*treeNode, 0, len(matches))
+	highlights := make(map[*treeNode][]int, len(matches))
+	for _, match := range matches {
+		if match.index < len(allNodes) {
+			matchingNodes = append(matchingNodes, allNodes[match.index])
+			highlights[allNodes[match.index]] = match.matched
 		}
 	}
 
 	m.searchMatchNodes = matchingNodes
-	m.visibleNodes = buildFilteredTree(searchRoot, matchingNodes, m.modeHidden)
+	m.searchHighlights = highlights
+	m.visibleNodes = m.searchResultNodes(searchRoot, matchingNodes)
 	m.displayed = len(m.visibleNodes)
 
 	if m.treeIdx >= len(m.visibleNodes) {
@@ -698,6 +916,23 @@ func (m *model) rebuildVisibleNodesWithSearch() {
 	}
 }
 
+// treeSearchRoot returns the node whose subtree is searched
+func (m *model) treeSearchRoot() *treeNode {
+	if m.treeSearchStartNode != nil {
+		return m.treeSearchStartNode
+	}
+	return m.treeRoot
+}
+
+// treeSearchRootPath returns the path of the node whose subtree is searched, or an empty string if
+// there is none
+func (m *model) treeSearchRootPath() string {
+	if searchRoot := m.treeSearchRoot(); searchRoot != nil {
+		return searchRoot.fullPath
+	}
+	return ""
+}
+
 // selectedTreeNode returns the currently selected tree node
 func (m *model) selectedTreeNode() *treeNode {
 	if m.treeIdx >= 0 && m.treeIdx < len(m.visibleNodes) {
@@ -712,14 +947,3 @@ func min(i, j int) int {
 	}
 	return j
 }
-
-// toggleTreeMark toggles mark on current tree node
-func (m *model) toggleTreeMark() {
-	if m.markedTreeNode(m.treeIdx) {
-		delete(m.marks, m.treeIdx)
-		m.modeMarks = len(m.marks) != 0
-	} else {
-		m.marks[m.treeIdx] = m.treeIdx // In tree mode, displayIdx == entryIdx conceptually
-		m.modeMarks = true
-	}
-}
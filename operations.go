@@ -0,0 +1,273 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/dkaslovsky/nav/internal/fileops"
+	"github.com/dkaslovsky/nav/internal/trash"
+)
+
+// operationTargets returns the paths of the marked entries, or of the entry under the cursor when
+// no entries are marked.
+func (m *model) operationTargets() ([]string, error) {
+	if m.modeMarks {
+		return m.markedPaths(), nil
+	}
+
+	path, err := m.cursorPath()
+	if err != nil {
+		return nil, err
+	}
+	return []string{path}, nil
+}
+
+// cursorPath returns the path of the entry under the cursor.
+func (m *model) cursorPath() (string, error) {
+	if m.modeTree {
+		node := m.selectedTreeNode()
+		if node == nil || node.entry == nil {
+			return "", errors.New("no entry under the cursor")
+		}
+		return node.fullPath, nil
+	}
+
+	selected, err := m.selected()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(m.path, selected.Name()), nil
+}
+
+// cursorDir returns the directory containing the entry under the cursor, in which new entries are
+// created.
+func (m *model) cursorDir() string {
+	if path, err := m.cursorPath(); err == nil {
+		return filepath.Dir(path)
+	}
+	return m.path
+}
+
+// resolvePath returns an absolute path for a path entered in a prompt, which may be relative to
+// the current directory or start with "~".
+func (m *model) resolvePath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~"+fileSeparator) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.path, path)
+	}
+	return filepath.Clean(path), nil
+}
+
+func (m *model) promptRename() {
+	path, err := m.cursorPath()
+	if err != nil {
+		m.setError(err, "failed to select entry")
+		return
+	}
+
+	m.setPrompt(&prompt{
+		label: "rename",
+		input: filepath.Base(path),
+		action: func(m *model, input string) (string, error) {
+			if err := fileops.ValidName(input); err != nil {
+				return "", err
+			}
+			dst := filepath.Join(filepath.Dir(path), input)
+			if err := fileops.Rename(path, dst); err != nil {
+				return "", err
+			}
+			return dst, nil
+		},
+		status: "failed to rename entry",
+	})
+}
+
+func (m *model) promptCreate(dir bool) {
+	label, status, create := "new file", "failed to create file", fileops.CreateFile
+	if dir {
+		label, status, create = "new directory", "failed to create directory", fileops.Mkdir
+	}
+
+	parent := m.cursorDir()
+	m.setPrompt(&prompt{
+		label: label,
+		action: func(m *model, input string) (string, error) {
+			if err := fileops.ValidName(input); err != nil {
+				return "", err
+			}
+			path := filepath.Join(parent, input)
+			if err := create(path); err != nil {
+				return "", err
+			}
+			return path, nil
+		},
+		status: status,
+	})
+}
+
+// promptTransfer prompts for the destination directory to copy or move the targeted entries to.
+func (m *model) promptTransfer(move bool) {
+	label, status, transfer := "copy", "failed to copy entries", fileops.Copy
+	if move {
+		label, status, transfer = "move", "failed to move entries", fileops.Move
+	}
+
+	paths, err := m.operationTargets()
+	if err != nil {
+		m.setError(err, "failed to select entries")
+		return
+	}
+
+	// The other pane is the destination in dual-pane mode, and otherwise the destination of the
+	// last copy or move, as entries cannot be copied or moved into their own directory.
+	input := ""
+	if m.modeDualPane {
+		input = m.otherPane.path + fileSeparator
+	} else if m.transferDir != "" {
+		input = m.transferDir + fileSeparator
+	}
+
+	m.setPrompt(&prompt{
+		label: fmt.Sprintf("%s %s to", label, describeTargets(paths)),
+		input: input,
+		action: func(m *model, input string) (string, error) {
+			dir, err := m.resolvePath(input)
+			if err != nil {
+				return "", err
+			}
+			m.transferDir = dir
+			info, err := os.Stat(dir)
+			if err != nil {
+				return "", err
+			}
+			if !info.IsDir() {
+				return "", fmt.Errorf("%s is not a directory", dir)
+			}
+
+			cursorPath := ""
+			for _, path := range paths {
+				dst := filepath.Join(dir, filepath.Base(path))
+				if err := transfer(path, dst); err != nil {
+					return cursorPath, err
+				}
+				cursorPath = dst
+			}
+			return cursorPath, nil
+		},
+		status: status,
+	})
+}
+
+// promptDelete asks for confirmation before moving the targeted entries to the trash.
+func (m *model) promptDelete() {
+	paths, err := m.operationTargets()
+	if err != nil {
+		m.setError(err, "failed to select entries")
+		return
+	}
+
+	m.setPrompt(&prompt{
+		label:   fmt.Sprintf("move %s to the trash", describeTargets(paths)),
+		confirm: true,
+		action: func(m *model, _ string) (string, error) {
+			for _, path := range paths {
+				t, err := trash.ForPath(path)
+				if err != nil {
+					return "", err
+				}
+				if _, err := t.Put(path); err != nil {
+					return "", err
+				}
+			}
+			return "", nil
+		},
+		status: "failed to move entries to the trash",
+	})
+}
+
+func describeTargets(paths []string) string {
+	if len(paths) == 1 {
+		return filepath.Base(paths[0])
+	}
+	return fmt.Sprintf("%d entries", len(paths))
+}
+
+// refresh reloads the entries after they are modified, keeping the cursor on the same entry or
+// moving it to cursorPath when it is not empty. Marks are removed from entries that no longer
+// exist.
+func (m *model) refresh(cursorPath string) tea.Cmd {
+	m.pruneMarks()
+	m.invalidateGitStatus()
+
+	if m.modeTrash {
+		if err := m.loadTrash(); err != nil {
+			m.setError(err, "failed to load trash")
+		}
+	}
+	if m.modeBookmarks {
+		if err := m.loadBookmarkPicker(); err != nil {
+			m.setError(err, "failed to load bookmarks")
+		}
+	}
+	if m.modeFrecency {
+		if err := m.loadFrecencyPicker(); err != nil {
+			m.setError(err, "failed to load frecent directories")
+		}
+	}
+	if m.modeBasket {
+		m.loadBasketPicker()
+	}
+	if m.modeDualPane {
+		if err := m.reloadOtherPane(); err != nil {
+			m.setError(err, "failed to refresh the other pane")
+		}
+	}
+
+	if m.modeTree {
+		if cursorPath == "" {
+			if node := m.selectedTreeNode(); node != nil {
+				cursorPath = node.fullPath
+			}
+		}
+
+		m.stopSearchIndexLoader()
+		if err := m.treeRoot.reload(m.sort); err != nil {
+			m.setError(err, "failed to refresh entries")
+		}
+		m.rebuildVisibleNodes()
+		m.syncWatches()
+		for i, node := range m.visibleNodes {
+			if node.fullPath == cursorPath {
+				m.treeIdx = i
+				break
+			}
+		}
+		// Restore the cursor within a collapsed directory when it is expanded.
+		if dir := filepath.Dir(cursorPath); cursorPath != "" && dir != m.path {
+			m.treeLastChild[dir] = filepath.Base(cursorPath)
+		}
+		m.adjustScrollOffset()
+		return m.startSearchIndexLoader(m.treeRoot)
+	}
+
+	// The cursor is restored by entry name from the cache when the view is rendered.
+	m.saveCursor()
+	if cache, ok := m.pathCache[m.path]; ok && filepath.Dir(cursorPath) == m.path {
+		cache.setCursorName(filepath.Base(cursorPath))
+	}
+	if err := m.list(); err != nil {
+		m.setError(err, "failed to refresh entries")
+	}
+	return nil
+}
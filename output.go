@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dkaslovsky/nav/internal/quote"
+)
+
+// outputFormat selects how the paths returned on exit are written to stdout.
+type outputFormat int
+
+const (
+	outputShell  outputFormat = iota // Quoted paths separated by spaces or newlines.
+	outputPrint0                     // Unescaped paths, each terminated by a NUL byte.
+	outputJSON                       // An array of objects describing each path.
+)
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch s {
+	case "shell":
+		return outputShell, nil
+	case "print0":
+		return outputPrint0, nil
+	case "json":
+		return outputJSON, nil
+	}
+	return 0, fmt.Errorf("invalid output format %q, expected shell, print0, or json", s)
+}
+
+// Separators of the paths returned on exit in the shell output format.
+const (
+	exitSeparatorSpace   = " "
+	exitSeparatorNewline = "\n"
+)
+
+// exitPaths holds the paths returned on exit.
+type exitPaths struct {
+	paths []string
+	sep   string // Separator in the shell output format.
+	key   string // Key that triggered the exit.
+}
+
+// setExit sets the paths returned on exit, which are separated by spaces in the shell output
+// format.
+func (m *model) setExit(paths ...string) {
+	m.setExitWithSeparator(exitSeparatorSpace, paths...)
+}
+
+func (m *model) setExitWithSeparator(sep string, paths ...string) {
+	m.modeExit = true
+	m.exitCode = 0
+	m.exit = &exitPaths{paths: paths, sep: sep, key: m.lastKey}
+}
+
+// exitOutput formats the paths returned on exit, or returns an empty string if there are none.
+func (m *model) exitOutput() (string, error) {
+	if m.exit == nil || len(m.exit.paths) == 0 {
+		return "", nil
+	}
+
+	switch m.output {
+	case outputPrint0:
+		return strings.Join(m.exit.paths, "\x00") + "\x00", nil
+	case outputJSON:
+		return formatJSONOutput(m.exit)
+	}
+
+	paths := make([]string, len(m.exit.paths))
+	for i, path := range m.exit.paths {
+		paths[i] = quote.Quote(path, m.quote)
+	}
+	return strings.Join(paths, m.exit.sep) + "\n", nil
+}
+
+// jsonOutputEntry describes a path in the JSON output format. Size and modification time are
+// omitted when the path cannot be read.
+type jsonOutputEntry struct {
+	Path  string     `json:"path"`
+	Type  string     `json:"type"`
+	Size  *int64     `json:"size,omitempty"`
+	MTime *time.Time `json:"mtime,omitempty"`
+	Key   string     `json:"key"`
+}
+
+func formatJSONOutput(exit *exitPaths) (string, error) {
+	entries := make([]jsonOutputEntry, 0, len(exit.paths))
+	for _, path := range exit.paths {
+		e := jsonOutputEntry{Path: path, Type: "unknown", Key: exit.key}
+		if info, err := os.Lstat(path); err == nil {
+			size, mtime := info.Size(), info.ModTime()
+			e.Type, e.Size, e.MTime = fileTypeName(info.Mode()), &size, &mtime
+		}
+		entries = append(entries, e)
+	}
+
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+func fileTypeName(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "file"
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode&fs.ModeNamedPipe != 0:
+		return "pipe"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeDevice != 0:
+		return "device"
+	}
+	return "other"
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/dkaslovsky/nav/internal/quote"
+)
+
+func TestExitOutput(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a 'b'\n$c")
+	if runtime.GOOS == "windows" {
+		file = filepath.Join(dir, "a 'b' $c")
+	}
+	if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		output outputFormat
+		quote  quote.Style
+		sep    string
+		paths  []string
+		want   string
+	}{
+		"shell_space_separated": {
+			output: outputShell,
+			quote:  quote.POSIX,
+			sep:    exitSeparatorSpace,
+			paths:  []string{"/tmp/a b", "/tmp/c"},
+			want:   "'/tmp/a b' /tmp/c\n",
+		},
+		"shell_newline_separated": {
+			output: outputShell,
+			quote:  quote.Fish,
+			sep:    exitSeparatorNewline,
+			paths:  []string{"/tmp/it's", "/tmp/c"},
+			want:   "'/tmp/it\\'s'\n/tmp/c\n",
+		},
+		"shell_unquoted": {
+			output: outputShell,
+			quote:  quote.None,
+			sep:    exitSeparatorSpace,
+			paths:  []string{"/tmp/a b"},
+			want:   "/tmp/a b\n",
+		},
+		"none": {
+			output: outputPrint0,
+			paths:  []string{},
+			want:   "",
+		},
+		"print0": {
+			output: outputPrint0,
+			sep:    exitSeparatorSpace,
+			paths:  []string{file, dir},
+			want:   file + "\x00" + dir + "\x00",
+		},
+		"print0_ignores_separator": {
+			output: outputPrint0,
+			sep:    exitSeparatorNewline,
+			paths:  []string{file},
+			want:   file + "\x00",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			m := newModel()
+			m.output = test.output
+			m.quote = test.quote
+			m.setExitWithSeparator(test.sep, test.paths...)
+
+			got, err := m.exitOutput()
+			if err != nil {
+				tt.Fatal(err)
+			}
+			if got != test.want {
+				tt.Fatalf("expected output %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestExitOutputJSON(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	m := newModel()
+	m.output = outputJSON
+	m.lastKey = "ctrl+x"
+	m.setExit(file, dir, missing)
+
+	out, err := m.exitOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out, err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(got))
+	}
+
+	want := []struct {
+		path     string
+		typ      string
+		hasStats bool
+	}{
+		{path: file, typ: "file", hasStats: true},
+		{path: dir, typ: "dir", hasStats: true},
+		{path: missing, typ: "unknown", hasStats: false},
+	}
+	for i, w := range want {
+		if got[i]["path"] != w.path || got[i]["type"] != w.typ || got[i]["key"] != "ctrl+x" {
+			t.Fatalf("unexpected entry %d: %v", i, got[i])
+		}
+		if _, ok := got[i]["mtime"]; ok != w.hasStats {
+			t.Fatalf("expected mtime present %t for entry %d: %v", w.hasStats, i, got[i])
+		}
+	}
+	if got[0]["size"] != float64(len("content")) {
+		t.Fatalf("expected size %d, got %v", len("content"), got[0]["size"])
+	}
+}
+
+func TestParseArgsFlagValue(t *testing.T) {
+	m := newModel()
+	if err := parseArgs([]string{"--output=json", "--quote=powershell", t.TempDir()}, m); err != nil {
+		t.Fatal(err)
+	}
+	if m.output != outputJSON || m.quote != quote.PowerShell {
+		t.Fatalf("expected JSON output quoted for PowerShell, got %v and %v", m.output, m.quote)
+	}
+
+	// Flags that take no value reject one rather than reading it as a path.
+	if err := parseArgs([]string{"--hidden=true", t.TempDir()}, newModel()); err == nil {
+		t.Fatal("expected an error for a value of --hidden")
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// paneSeparator separates the panes in dual-pane mode.
+const paneSeparator = "│"
+
+// toggleDualPane splits the screen into two panes, the new pane showing the current directory in
+// the current view mode, or returns to the left pane alone.
+func (m *model) toggleDualPane() tea.Cmd {
+	if m.modeDualPane {
+		var cmd tea.Cmd
+		if m.paneRight {
+			cmd = m.switchPane()
+		}
+		m.modeDualPane = false
+		m.otherPane = nil
+		m.resize()
+		return cmd
+	}
+
+	t := newTab()
+	t.path = m.path
+	t.modeTree = m.modeTree
+
+	m.modeDualPane = true
+	m.otherPane = t
+	m.paneRight = false
+	m.resize()
+	if err := m.reloadOtherPane(); err != nil {
+		m.setError(err, "failed to read directory")
+		return m.toggleDualPane()
+	}
+	return nil
+}
+
+// switchPane moves the focus to the other pane, which then holds the tab. The search of the pane
+// losing focus is cleared because only the focused pane is indexed.
+func (m *model) switchPane() tea.Cmd {
+	if !m.modeDualPane {
+		return nil
+	}
+	m.clearSearch()
+	if m.modeTree {
+		m.rebuildVisibleNodes()
+	}
+	m.saveCursor()
+
+	m.swapPanes()
+	m.tabs[m.tabIdx] = m.tab
+	return m.activateTab()
+}
+
+// swapPanes makes the other pane current, moving the dual-pane state of the tab to it. Swapping
+// again restores the focused pane.
+func (m *model) swapPanes() {
+	focused, other := m.tab, m.otherPane
+	other.modeDualPane, other.otherPane, other.paneRight = true, focused, !focused.paneRight
+	focused.modeDualPane, focused.otherPane, focused.paneRight = false, nil, false
+	m.tab = other
+}
+
+// reloadOtherPane reloads the entries of the pane that is not focused, removing marks from entries
+// that no longer exist.
+func (m *model) reloadOtherPane() error {
+	// The focused pane is watched again once it is restored.
+	defer m.syncWatches()
+	m.swapPanes()
+	defer m.swapPanes()
+
+	m.pruneMarks()
+	if !m.modeTree {
+		return m.list()
+	}
+	if m.treeRoot == nil {
+		return m.loadTree()
+	}
+	if err := m.treeRoot.reload(m.sort); err != nil {
+		return err
+	}
+	m.rebuildVisibleNodes()
+	return nil
+}
+
+// panePaths returns the directories of the left and right panes in dual-pane mode, or of the
+// current directory otherwise.
+func (m *model) panePaths() []string {
+	if !m.modeDualPane {
+		return []string{m.path}
+	}
+	if m.paneRight {
+		return []string{m.otherPane.path, m.path}
+	}
+	return []string{m.path, m.otherPane.path}
+}
+
+// paneView renders the current directory in the current view mode.
+func (m *model) paneView() string {
+	if m.modeTree {
+		return m.treeView()
+	}
+	return m.normalView()
+}
+
+// dualPaneView renders the panes side by side.
+func (m *model) dualPaneView() string {
+	focused := strings.Split(m.paneView(), "\n")
+
+	// The preview shows the entry under the cursor of the focused pane, so the other pane is
+	// rendered without it.
+	preview := m.modePreview
+	m.modePreview = false
+	m.swapPanes()
+	other := strings.Split(m.paneView(), "\n")
+	m.swapPanes()
+	m.modePreview = preview
+
+	left, right := focused, other
+	if m.paneRight {
+		left, right = other, focused
+	}
+
+	separator := previewRendererSeparator.Render(paneSeparator)
+	lines := make([]string, max(len(left), len(right)))
+	for i := range lines {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		lines[i] = fitWidth(l, m.width) + separator + fitWidth(r, m.width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fitWidth truncates or pads a rendered line to width.
+func fitWidth(line string, width int) string {
+	line = lipgloss.NewStyle().MaxWidth(width).Render(line)
+	if w := lipgloss.Width(line); w < width {
+		line += strings.Repeat(" ", width-w)
+	}
+	return line
+}
+
+// comparePanes marks the entries of each pane that are missing from the other pane or, for files,
+// differ in size or are newer than the file of the same name in the other pane.
+func (m *model) comparePanes() {
+	if !m.modeDualPane {
+		return
+	}
+	other, _ := m.otherPane.listedEntries()
+	m.markDifferences(other)
+
+	m.swapPanes()
+	other, _ = m.otherPane.listedEntries()
+	m.markDifferences(other)
+	m.swapPanes()
+}
+
+// markDifferences marks the listed entries that differ from the other entries and unmarks the
+// others. Marks on entries of other directories are kept.
+func (m *model) markDifferences(other []*entry) {
+	entries, paths := m.listedEntries()
+	marks := m.markSet()
+	for _, path := range paths {
+		delete(marks, path)
+	}
+	for _, i := range compareEntries(entries, other) {
+		marks[paths[i]] = entries[i]
+	}
+	m.modeMarks = len(marks) != 0
+}
+
+// listedEntries returns the entries listed in the directory of the tab, which are the top level
+// of the tree in tree view mode, and their paths.
+func (t *tab) listedEntries() ([]*entry, []string) {
+	if !t.modeTree {
+		paths := make([]string, len(t.entries))
+		for i, ent := range t.entries {
+			paths[i] = filepath.Join(t.path, ent.Name())
+		}
+		return t.entries, paths
+	}
+
+	entries, paths := []*entry{}, []string{}
+	for _, node := range t.visibleNodes {
+		if node.parent == t.treeRoot && node.entry != nil {
+			entries = append(entries, node.entry)
+			paths = append(paths, node.fullPath)
+		}
+	}
+	return entries, paths
+}
+
+// compareEntries returns the indexes of the entries that are missing from other or, for files,
+// differ in size or are newer than the file of the same name in other.
+func compareEntries(entries []*entry, other []*entry) []int {
+	byName := make(map[string]*entry, len(other))
+	for _, o := range other {
+		byName[o.Name()] = o
+	}
+
+	idxs := []int{}
+	for i, e := range entries {
+		o, ok := byName[e.Name()]
+		if !ok || e.IsDir() != o.IsDir() {
+			idxs = append(idxs, i)
+			continue
+		}
+		if e.IsDir() || e.info == nil || o.info == nil {
+			continue
+		}
+		if e.info.Size() != o.info.Size() || e.info.ModTime().After(o.info.ModTime()) {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompareEntries(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	newFile := func(name string, size int64, modTime time.Time) *entry {
+		e, err := newEntry(&mockDirEntry{name: name, size: size, modTime: modTime})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	newDir := func(name string, modTime time.Time) *entry {
+		e, err := newEntry(&mockDirEntry{name: name, mode: fs.ModeDir, modTime: modTime})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	tests := map[string]struct {
+		entries []*entry
+		other   []*entry
+		want    []int
+	}{
+		"same": {
+			entries: []*entry{newFile("a", 1, now), newDir("d", now)},
+			other:   []*entry{newDir("d", earlier), newFile("a", 1, now)},
+			want:    []int{},
+		},
+		"missing": {
+			entries: []*entry{newFile("a", 1, now), newFile("b", 1, now)},
+			other:   []*entry{newFile("b", 1, now)},
+			want:    []int{0},
+		},
+		"size": {
+			entries: []*entry{newFile("a", 1, earlier)},
+			other:   []*entry{newFile("a", 2, now)},
+			want:    []int{0},
+		},
+		"newer": {
+			entries: []*entry{newFile("a", 1, now), newFile("b", 1, earlier)},
+			other:   []*entry{newFile("a", 1, earlier), newFile("b", 1, now)},
+			want:    []int{0},
+		},
+		"file_and_directory": {
+			entries: []*entry{newFile("a", 1, now)},
+			other:   []*entry{newDir("a", now)},
+			want:    []int{0},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			if got := compareEntries(test.entries, test.other); !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected indexes %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestDualPane(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{"x", "a/x", "a/y"}
+	writeTestFiles(t, dir, files...)
+	// Files of the same name compare equal only with the same modification time.
+	mtime := time.Now()
+	for _, file := range files {
+		if err := os.Chtimes(filepath.Join(dir, filepath.FromSlash(file)), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := filepath.Join(dir, "a")
+
+	m := newModel()
+	m.path = dir
+	if err := m.list(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The new pane starts in the current directory and both panes share the width.
+	m.toggleDualPane()
+	if !m.modeDualPane || m.otherPane.path != dir || len(m.otherPane.entries) != 2 {
+		t.Fatalf("expected a second pane at %s, got %+v", dir, m.otherPane)
+	}
+	if want := (m.screenWidth - 1) / 2; m.width != want {
+		t.Fatalf("expected width %d, got %d", want, m.width)
+	}
+
+	// Switching panes keeps the directory of each pane.
+	m.jumpTo(a)
+	m.switchPane()
+	if !m.paneRight || m.path != dir || m.otherPane.path != a || m.tabs[m.tabIdx] != m.tab {
+		t.Fatalf("expected focus on the right pane at %s, got %s", dir, m.path)
+	}
+	if want := []string{a, dir}; !reflect.DeepEqual(m.panePaths(), want) {
+		t.Fatalf("expected pane paths %v, got %v", want, m.panePaths())
+	}
+
+	// Comparing marks the entries missing from the other pane in both panes, keeping the marks on
+	// entries of other directories.
+	m.basket[filepath.Join(a, "x")] = m.otherPane.entries[0]
+	m.comparePanes()
+	if want := []string{a, filepath.Join(a, "x")}; !reflect.DeepEqual(m.markedPaths(), want) {
+		t.Fatalf("expected marked paths %v, got %v", want, m.markedPaths())
+	}
+	if _, marked := m.otherPane.basket[filepath.Join(a, "y")]; !marked || len(m.otherPane.basket) != 1 {
+		t.Fatalf("expected the file y to be marked in the other pane, got %v", m.otherPane.basket)
+	}
+
+	// Each tab has its own panes.
+	m.openTab()
+	if m.modeDualPane || m.width != m.screenWidth {
+		t.Fatalf("expected a new tab with a single pane, got width %d", m.width)
+	}
+	m.cycleTab(-1)
+	if !m.modeDualPane || !m.paneRight || m.path != dir || m.otherPane.path != a {
+		t.Fatalf("expected focus on the right pane at %s, got %s", dir, m.path)
+	}
+
+	// Returning to a single pane restores the left pane into the tab.
+	m.toggleDualPane()
+	if m.modeDualPane || m.otherPane != nil || m.width != m.screenWidth || m.tabs[m.tabIdx] != m.tab {
+		t.Fatalf("expected a single pane with full width, got width %d", m.width)
+	}
+	if want := []string{a}; !reflect.DeepEqual(m.panePaths(), want) {
+		t.Fatalf("expected pane paths %v, got %v", want, m.panePaths())
+	}
+	if want := []string{filepath.Join(a, "y")}; !reflect.DeepEqual(m.markedPaths(), want) {
+		t.Fatalf("expected the marks of the left pane %v, got %v", want, m.markedPaths())
+	}
+}
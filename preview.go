@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	previewMaxLines = 500       // Maximum number of lines loaded for a preview.
+	previewMaxBytes = 64 * 1024 // Maximum number of bytes read from a file for a preview.
+	previewTabWidth = 4
+
+	previewSeparator = " │ "
+)
+
+// preview holds the content of the preview pane for the entry at path.
+type preview struct {
+	path    string
+	lines   []string
+	loading bool
+}
+
+// previewRefreshMsg requests that the preview be updated for the entry under the cursor. It is
+// sent after the view is rendered so that the cursor position has been resolved.
+type previewRefreshMsg struct{}
+
+// previewMsg delivers the content loaded for the preview of the entry at path.
+type previewMsg struct {
+	path  string
+	lines []string
+}
+
+func refreshPreviewCmd() tea.Msg {
+	return previewRefreshMsg{}
+}
+
+// previewTarget returns the path of the entry under the cursor.
+func (m *model) previewTarget() (string, bool) {
+	if m.modeTree {
+		node := m.selectedTreeNode()
+		if node == nil || node.entry == nil {
+			return "", false
+		}
+		return node.fullPath, true
+	}
+
+	selected, err := m.selected()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(m.path, selected.Name()), true
+}
+
+// refreshPreview returns a command to load the preview of the entry under the cursor if it is not
+// already loaded or loading. Results for entries that are no longer under the cursor are discarded.
+func (m *model) refreshPreview() tea.Cmd {
+	if !m.modePreview {
+		m.preview = nil
+		return nil
+	}
+
+	path, ok := m.previewTarget()
+	if !ok {
+		m.preview = nil
+		return nil
+	}
+	if m.preview != nil && m.preview.path == path {
+		return nil
+	}
+
+	m.preview = &preview{path: path, loading: true}
+	modeHidden, order := m.modeHidden, m.sort
+	return func() tea.Msg {
+		return previewMsg{
+			path:  path,
+			lines: loadPreview(path, modeHidden, order),
+		}
+	}
+}
+
+// loadPreview returns the preview lines for a path: the first lines of a text file, the listing of
+// a directory, or a summary for binary and special files. Symlinks are followed.
+func loadPreview(path string, modeHidden bool, order sortOrder) []string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return []string{fmt.Sprintf("(%v)", errors.Unwrap(err))}
+	}
+
+	switch {
+	case info.IsDir():
+		return previewDir(path, modeHidden, order)
+	case info.Mode().IsRegular():
+		return previewFile(path, info.Size())
+	default:
+		// Reading from special files such as named pipes can block indefinitely.
+		return []string{fmt.Sprintf("(special file, %s)", info.Mode().Type())}
+	}
+}
+
+func previewDir(path string, modeHidden bool, order sortOrder) []string {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return []string{fmt.Sprintf("(%v)", errors.Unwrap(err))}
+	}
+
+	entries := make([]*entry, 0, len(files))
+	for _, file := range files {
+		ent, err := newEntry(file)
+		if err != nil {
+			continue
+		}
+		if !modeHidden && ent.hasMode(entryModeHidden) {
+			continue
+		}
+		entries = append(entries, ent)
+	}
+	if len(entries) == 0 {
+		return []string{"(empty directory)"}
+	}
+	sortEntries(entries, order)
+
+	lines := []string{}
+	for i, ent := range entries {
+		if i == previewMaxLines {
+			lines = append(lines, fmt.Sprintf("(%d more)", len(entries)-i))
+			break
+		}
+		name := sanitizePreviewLine(ent.Name())
+		if ent.hasMode(entryModeDir) {
+			name += fileSeparator
+		}
+		lines = append(lines, name)
+	}
+	return lines
+}
+
+func previewFile(path string, size int64) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return []string{fmt.Sprintf("(%v)", errors.Unwrap(err))}
+	}
+	defer f.Close()
+
+	buf := make([]byte, previewMaxBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return []string{fmt.Sprintf("(%v)", err)}
+	}
+	buf = buf[:n]
+	if n == 0 {
+		return []string{"(empty file)"}
+	}
+
+	// Drop a trailing partial line, which may end in a partial rune, when the file is truncated. A
+	// single line is kept without its partial rune.
+	if int64(n) < size {
+		if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+			buf = buf[:i]
+		} else {
+			buf = trimPartialRune(buf)
+		}
+	}
+	if bytes.IndexByte(buf, 0) >= 0 || !utf8.Valid(buf) {
+		return []string{fmt.Sprintf("binary file, %d bytes", size)}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+	if len(lines) > previewMaxLines {
+		lines = lines[:previewMaxLines]
+	}
+	for i, line := range lines {
+		lines[i] = sanitizePreviewLine(line)
+	}
+	return lines
+}
+
+// trimPartialRune drops an incomplete rune from the end of buf.
+func trimPartialRune(buf []byte) []byte {
+	for i := len(buf) - 1; i >= 0 && i > len(buf)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(buf[i]) {
+			if !utf8.FullRune(buf[i:]) {
+				return buf[:i]
+			}
+			break
+		}
+	}
+	return buf
+}
+
+// sanitizePreviewLine expands tabs and replaces control characters so that file content cannot
+// emit terminal escape sequences.
+func sanitizePreviewLine(line string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSuffix(line, "\r") {
+		switch {
+		case r == '\t':
+			b.WriteString(strings.Repeat(" ", previewTabWidth))
+		case unicode.IsControl(r):
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// previewWidth returns the width of the preview pane, or zero when it is not shown.
+func (m *model) previewWidth() int {
+	if !m.modePreview {
+		return 0
+	}
+	return m.width / 2
+}
+
+// contentWidth returns the width available to the grid and tree views.
+func (m *model) contentWidth() int {
+	if !m.modePreview {
+		return m.width
+	}
+	return m.width - m.previewWidth() - lipgloss.Width(previewSeparator)
+}
+
+// joinPreview places the preview pane to the right of the lines of a view, extending the view to
+// the available height when the preview is longer.
+func (m *model) joinPreview(lines []string) []string {
+	if !m.modePreview {
+		return lines
+	}
+
+	var content []string
+	if m.preview != nil {
+		content = m.preview.lines
+		if m.preview.loading {
+			content = []string{"loading..."}
+		}
+	}
+
+	height := max(len(lines), min(len(content), m.height-3))
+	contentWidth := m.contentWidth()
+	truncate := func(s string, width int) string {
+		return lipgloss.NewStyle().MaxWidth(width).Render(s)
+	}
+
+	joined := make([]string, height)
+	for i := range joined {
+		line := ""
+		if i < len(lines) {
+			line = truncate(lines[i], contentWidth)
+		}
+		if w := lipgloss.Width(line); w < contentWidth {
+			line += strings.Repeat(" ", contentWidth-w)
+		}
+
+		previewLine := ""
+		if i < len(content) {
+			previewLine = truncate(content[i], m.previewWidth())
+		}
+		joined[i] = line + previewRendererSeparator.Render(previewSeparator) + previewLine
+	}
+	return joined
+}
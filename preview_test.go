@@ -0,0 +1,186 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestLoadPreviewFile(t *testing.T) {
+	repeat := func(line string, n int) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = line
+		}
+		return lines
+	}
+
+	tests := map[string]struct {
+		content string
+		want    []string
+	}{
+		"text": {
+			content: "first\nsecond\n",
+			want:    []string{"first", "second"},
+		},
+		"empty": {
+			content: "",
+			want:    []string{"(empty file)"},
+		},
+		"crlf_and_tabs": {
+			content: "a\tb\r\nc\r\n",
+			want:    []string{"a    b", "c"},
+		},
+		"control_characters": {
+			content: "\x1b[31mred\x1b[0m\a\n\u0085next",
+			want:    []string{"?[31mred?[0m?", "?next"},
+		},
+		"nul_byte": {
+			content: "text\x00more",
+			want:    []string{"binary file, 9 bytes"},
+		},
+		"invalid_utf8": {
+			content: "text\xff\xfe",
+			want:    []string{"binary file, 6 bytes"},
+		},
+		"line_limit": {
+			content: strings.Repeat("line\n", previewMaxLines+10),
+			want:    repeat("line", previewMaxLines),
+		},
+		"size_limit": {
+			content: strings.Repeat("x", previewMaxBytes+10),
+			want:    []string{strings.Repeat("x", previewMaxBytes)},
+		},
+		// The read stops in the middle of a rune, which is dropped with the partial last line.
+		"size_limit_partial_rune": {
+			content: strings.Repeat("ab\n", previewMaxBytes/3) + "é\n",
+			want:    repeat("ab", previewMaxLines),
+		},
+		// The read stops in the middle of a rune of a single line, which is kept without the rune.
+		"size_limit_single_line_partial_rune": {
+			content: "x" + strings.Repeat("é", previewMaxBytes/2),
+			want:    []string{"x" + strings.Repeat("é", previewMaxBytes/2-1)},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			path := filepath.Join(tt.TempDir(), "file")
+			if err := os.WriteFile(path, []byte(test.content), 0o644); err != nil {
+				tt.Fatal(err)
+			}
+			got := loadPreview(path, false, defaultSortOrder())
+			if !reflect.DeepEqual(got, test.want) {
+				if len(got) > 3 {
+					tt.Fatalf("expected %d lines starting %q, got %d lines starting %q",
+						len(test.want), test.want[:min(3, len(test.want))], len(got), got[:3])
+				}
+				tt.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadPreviewDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int{"a.txt": 1, "B.txt": 3, ".hidden": 2} {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"dir", filepath.Join("empty", ".only-hidden")} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		path       string
+		modeHidden bool
+		order      sortOrder
+		want       []string
+	}{
+		"default": {
+			order: defaultSortOrder(),
+			want:  []string{"dir/", "empty/", "B.txt", "a.txt"},
+		},
+		"hidden": {
+			modeHidden: true,
+			order:      defaultSortOrder(),
+			want:       []string{"dir/", "empty/", "B.txt", "a.txt", ".hidden"},
+		},
+		"ignore_case_mixed": {
+			order: sortOrder{mode: sortModeName, ignoreCase: true},
+			want:  []string{"a.txt", "B.txt", "dir/", "empty/"},
+		},
+		"size_reversed": {
+			order: sortOrder{mode: sortModeSize, reverse: true, dirsFirst: true},
+			want:  []string{"empty/", "dir/", "a.txt", "B.txt"},
+		},
+		"only_hidden_entries": {
+			path:  "empty",
+			order: defaultSortOrder(),
+			want:  []string{"(empty directory)"},
+		},
+		"only_hidden_entries_shown": {
+			path:       "empty",
+			modeHidden: true,
+			order:      defaultSortOrder(),
+			want:       []string{".only-hidden/"},
+		},
+		"missing": {
+			path:  "missing",
+			order: defaultSortOrder(),
+			want:  []string{"(no such file or directory)"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got := loadPreview(filepath.Join(dir, test.path), test.modeHidden, test.order)
+			if !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestJoinPreview(t *testing.T) {
+	m := newModel()
+	m.modePreview = true
+	m.width = 23
+	m.height = 6
+
+	// The preview extends the view up to the available height and both are truncated to their widths.
+	m.preview = &preview{path: "file", lines: []string{"1", "2", "3", "the fourth line", "5"}}
+	joined := m.joinPreview([]string{"a view wider than its pane"})
+	if len(joined) != m.height-3 {
+		t.Fatalf("expected %d lines, got %d", m.height-3, len(joined))
+	}
+	for i, line := range joined {
+		if w := lipgloss.Width(line); w > m.width {
+			t.Fatalf("expected line %d to fit in %d columns, got %d", i, m.width, w)
+		}
+	}
+	if !strings.HasPrefix(joined[0], "a view w") || !strings.HasSuffix(joined[0], "1") {
+		t.Fatalf("unexpected first line %q", joined[0])
+	}
+
+	// The view is not cut to the height of the preview.
+	m.preview.loading = true
+	joined = m.joinPreview([]string{"a", "b", "c", "d", "e"})
+	if len(joined) != 5 || !strings.HasSuffix(joined[0], "loading...") || !strings.HasSuffix(joined[1], previewSeparator) {
+		t.Fatalf("expected the view with a loading preview, got %q", joined)
+	}
+
+	m.modePreview = false
+	if joined := m.joinPreview([]string{"a"}); !reflect.DeepEqual(joined, []string{"a"}) {
+		t.Fatalf("expected the view unchanged, got %q", joined)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prompt reads a line of input, or a single confirmation key, in the location bar and passes it
+// to an action when submitted.
+type prompt struct {
+	label   string
+	input   string
+	confirm bool // Submit on "y" and cancel on any other key.
+
+	// action performs the prompted operation and returns the path of the entry to place the cursor
+	// on, or an empty string to keep the current cursor.
+	action func(m *model, input string) (string, error)
+	// status describes a failure of the action.
+	status string
+}
+
+func (m *model) setPrompt(p *prompt) {
+	m.modePrompt = true
+	m.prompt = p
+}
+
+func (m *model) clearPrompt() {
+	m.modePrompt = false
+	m.prompt = nil
+}
+
+// submitPrompt runs the action of the prompt and refreshes the entries, which may have changed
+// even if the action failed partway.
+func (m *model) submitPrompt() tea.Cmd {
+	p := m.prompt
+	m.clearPrompt()
+
+	cursorPath, err := p.action(m, p.input)
+	cmd := m.refresh(cursorPath)
+	if err != nil {
+		m.setError(err, p.status)
+	}
+	return cmd
+}
+
+func (m *model) promptBar() string {
+	label := fmt.Sprintf(" %s: ", m.prompt.label)
+	if m.prompt.confirm {
+		label = fmt.Sprintf(" %s? (y/N) ", m.prompt.label)
+	}
+	return barRendererLocation.Render(label) + barRendererSearch.Render(m.prompt.input)
+}
+
+func actionModePrompt(m *model, msg tea.KeyMsg, esc bool) actionResult {
+	if esc || key.Matches(msg, keyEsc) {
+		m.clearPrompt()
+		return newActionResult(nil)
+	}
+
+	if m.prompt.confirm {
+		if msg.String() == "y" || msg.String() == "Y" {
+			return newActionResult(m.submitPrompt())
+		}
+		m.clearPrompt()
+		return newActionResult(nil)
+	}
+
+	switch {
+
+	// Do not allow remapped escape key character as part of the input.
+	case key.Matches(msg, m.esc.key):
+
+	case key.Matches(msg, keySelect):
+		return newActionResult(m.submitPrompt())
+
+	case key.Matches(msg, keyBack):
+		if runes := []rune(m.prompt.input); len(runes) > 0 {
+			m.prompt.input = string(runes[:len(runes)-1])
+		}
+
+	case msg.Type == tea.KeyRunes:
+		m.prompt.input += string(msg.Runes)
+
+	case msg.Type == tea.KeySpace:
+		m.prompt.input += " "
+
+	}
+
+	// Unconditional return to disable all other functionality.
+	return newActionResult(nil)
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// searchQuery is an extended search query in the style of fzf: a conjunction of groups, each of
+// which matches if any of its terms matches. Terms are separated by spaces and the terms of a
+// group by "|". A space is included in a term by escaping it as "\ ".
+type searchQuery [][]queryTerm
+
+// queryTermKind selects how a term of a search query matches.
+type queryTermKind int
+
+const (
+	termPlain  queryTermKind = iota // text: matched with the search strategy.
+	termExact                       // 'text: contains text.
+	termPrefix                      // ^text: starts with text.
+	termSuffix                      // text$: ends with text.
+	termEqual                       // ^text$: is text.
+)
+
+// queryTerm is a term of a search query. Terms other than plain terms ignore case unless they
+// contain an uppercase letter.
+type queryTerm struct {
+	text   string
+	kind   queryTermKind
+	negate bool // !text: does not contain text, combined with the other operators.
+	path   bool // text contains "/" and is matched against the relative path.
+}
+
+// parseSearchQuery parses an extended search query. Terms that are empty after removing their
+// operators, such as a lone "!" while a term is being typed, are skipped.
+func parseSearchQuery(s string) searchQuery {
+	q := searchQuery{}
+	or := false
+	for _, token := range splitSearchQuery(s) {
+		if token == "|" {
+			or = len(q) > 0
+			continue
+		}
+		term, ok := parseQueryTerm(token)
+		if !ok {
+			continue
+		}
+		if or {
+			q[len(q)-1] = append(q[len(q)-1], term)
+		} else {
+			q = append(q, []queryTerm{term})
+		}
+		or = false
+	}
+	return q
+}
+
+// splitSearchQuery splits a query on spaces that are not escaped by a backslash.
+func splitSearchQuery(s string) []string {
+	tokens := []string{}
+	var token strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == ' ':
+			token.WriteByte(' ')
+			i++
+		case s[i] == ' ':
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+		default:
+			token.WriteByte(s[i])
+		}
+	}
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+	return tokens
+}
+
+func parseQueryTerm(token string) (queryTerm, bool) {
+	t := queryTerm{kind: termPlain}
+	if strings.HasPrefix(token, "!") {
+		// As in fzf, negated terms match exactly.
+		t.negate, t.kind = true, termExact
+		token = token[1:]
+	}
+
+	switch {
+	case strings.HasPrefix(token, "'"):
+		t.kind = termExact
+		token = token[1:]
+	case strings.HasPrefix(token, "^"):
+		t.kind = termPrefix
+		token = token[1:]
+	}
+	if t.kind != termExact || t.negate {
+		if trimmed, ok := strings.CutSuffix(token, "$"); ok {
+			if t.kind == termPrefix {
+				t.kind = termEqual
+			} else {
+				t.kind = termSuffix
+			}
+			token = trimmed
+		}
+	}
+
+	if token == "" {
+		return queryTerm{}, false
+	}
+	t.text = token
+	t.path = strings.Contains(token, "/")
+	return t, true
+}
+
+// matchSearchQuery returns the names matched by every group of the query, ordered by the sum of the
+// scores of the best matching term of each group and otherwise in the order of the names. Plain
+// terms are matched with the given strategy. Terms containing "/" are matched against the relative
+// paths returned by paths, or against the names if paths is nil. The matched characters of the
+// names are those matched by the best positive term of each group.
+func matchSearchQuery(q searchQuery, strategy searchStrategy, names []string, paths func() []string) []searchMatch {
+	var pathTargets []string
+	targets := func(t queryTerm) []string {
+		if !t.path || paths == nil {
+			return names
+		}
+		if pathTargets == nil {
+			pathTargets = paths()
+		}
+		return pathTargets
+	}
+
+	alive := make([]bool, len(names))
+	results := make([]searchMatch, len(names))
+	for i := range names {
+		alive[i] = true
+		results[i].index = i
+	}
+
+	for _, group := range q {
+		matched := make([]bool, len(names))
+		positive := make([]bool, len(names))
+		best := make([]searchMatch, len(names))
+		for _, term := range group {
+			termTargets := targets(term)
+			found := term.find(strategy, termTargets)
+			for i := range names {
+				if !alive[i] {
+					continue
+				}
+				match, hit := found[i]
+				if term.negate {
+					matched[i] = matched[i] || !hit
+					continue
+				}
+				if !hit || (positive[i] && match.score <= best[i].score) {
+					continue
+				}
+				matched[i], positive[i] = true, true
+				best[i].score = match.score
+				best[i].matched = match.matched
+				if term.path && paths != nil {
+					best[i].matched = nameOffsets(match.matched, termTargets[i], names[i])
+				}
+			}
+		}
+		for i := range names {
+			if !matched[i] {
+				alive[i] = false
+				continue
+			}
+			results[i].score += best[i].score
+			results[i].matched = append(results[i].matched, best[i].matched...)
+		}
+	}
+
+	matches := []searchMatch{}
+	for i, result := range results {
+		if !alive[i] {
+			continue
+		}
+		if len(result.matched) > 0 {
+			sort.Ints(result.matched)
+			result.matched = uniqueInts(result.matched)
+		}
+		matches = append(matches, result)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	return matches
+}
+
+// Bonuses added to the score of each character of a relative path matched by a query, so that
+// matches in the final element of the path and at the start of path segments rank first.
+const (
+	pathNameBonus    = 4
+	pathSegmentBonus = 6
+)
+
+// matchSearchQueryPaths matches a query against relative paths with "/" separators rather than
+// names. The matched characters are offsets in the paths.
+func matchSearchQueryPaths(q searchQuery, strategy searchStrategy, paths []string) []searchMatch {
+	matches := matchSearchQuery(q, strategy, paths, nil)
+	for i := range matches {
+		matches[i].score += pathMatchBonus(paths[matches[i].index], matches[i].matched)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	return matches
+}
+
+func pathMatchBonus(path string, offsets []int) int {
+	nameStart := strings.LastIndexByte(path, '/') + 1
+	bonus := 0
+	for _, offset := range offsets {
+		if offset >= nameStart {
+			bonus += pathNameBonus
+		}
+		if offset == 0 || path[offset-1] == '/' {
+			bonus += pathSegmentBonus
+		}
+	}
+	return bonus
+}
+
+// find returns the matches of the term, ignoring negation, keyed by the index of the target.
+func (t queryTerm) find(strategy searchStrategy, targets []string) map[int]searchMatch {
+	found := map[int]searchMatch{}
+	if t.kind == termPlain {
+		for _, match := range searchNames(t.text, strategy, targets) {
+			found[match.index] = match
+		}
+		return found
+	}
+
+	ignoreCase := strings.ToLower(t.text) == t.text
+	for i, target := range targets {
+		start, end, ok := t.findExact(target, ignoreCase)
+		if !ok {
+			continue
+		}
+		found[i] = searchMatch{
+			index:   i,
+			matched: runeOffsets(target, start, end),
+			score:   exactScore(target, start, end),
+		}
+	}
+	return found
+}
+
+// findExact returns the byte range of the text of a term other than a plain term in s.
+func (t queryTerm) findExact(s string, ignoreCase bool) (int, int, bool) {
+	hasPrefix := func(s string) (int, bool) {
+		if ignoreCase {
+			return hasPrefixFold(s, t.text)
+		}
+		return len(t.text), strings.HasPrefix(s, t.text)
+	}
+
+	switch t.kind {
+	case termPrefix, termEqual:
+		end, ok := hasPrefix(s)
+		if !ok || (t.kind == termEqual && end != len(s)) {
+			return 0, 0, false
+		}
+		return 0, end, true
+	case termSuffix:
+		for start := 0; start < len(s); {
+			if end, ok := hasPrefix(s[start:]); ok && start+end == len(s) {
+				return start, len(s), true
+			}
+			_, size := utf8.DecodeRuneInString(s[start:])
+			start += size
+		}
+		return 0, 0, false
+	}
+
+	if ignoreCase {
+		return indexFold(s, t.text)
+	}
+	start := strings.Index(s, t.text)
+	return start, start + len(t.text), start >= 0
+}
+
+// exactScore scores an exact match by its length, with a bonus for starting at the beginning of
+// the target or of a word, which are the matches most likely to be intended.
+func exactScore(s string, start int, end int) int {
+	score := 2 * utf8.RuneCountInString(s[start:end])
+	if start == 0 || strings.IndexByte("/_-. ", s[start-1]) >= 0 {
+		score += 8
+	}
+	return score
+}
+
+// nameOffsets converts byte offsets in a path to offsets in its final element, dropping those
+// before it.
+func nameOffsets(offsets []int, path string, name string) []int {
+	if !strings.HasSuffix(path, name) {
+		return nil
+	}
+	shift := len(path) - len(name)
+	converted := []int{}
+	for _, offset := range offsets {
+		if offset >= shift {
+			converted = append(converted, offset-shift)
+		}
+	}
+	return converted
+}
+
+// pathOffsets converts byte offsets in the final element of a path, which starts at the given byte
+// offset, to offsets in the path.
+func pathOffsets(offsets []int, nameStart int) []int {
+	converted := make([]int, len(offsets))
+	for i, offset := range offsets {
+		converted[i] = nameStart + offset
+	}
+	return converted
+}
+
+// searchPath returns the path relative to root, with "/" separators, that terms of a search query
+// containing "/" are matched against.
+func searchPath(root string, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func uniqueInts(sorted []int) []int {
+	unique := sorted[:0]
+	for i, n := range sorted {
+		if i == 0 || n != sorted[i-1] {
+			unique = append(unique, n)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		want  searchQuery
+	}{
+		"empty": {
+			query: "",
+			want:  searchQuery{},
+		},
+		"plain": {
+			query: "main",
+			want:  searchQuery{{{text: "main"}}},
+		},
+		"and": {
+			query: "  main  go ",
+			want:  searchQuery{{{text: "main"}}, {{text: "go"}}},
+		},
+		"operators": {
+			query: "'exact ^prefix suffix$ ^equal$",
+			want: searchQuery{
+				{{text: "exact", kind: termExact}},
+				{{text: "prefix", kind: termPrefix}},
+				{{text: "suffix", kind: termSuffix}},
+				{{text: "equal", kind: termEqual}},
+			},
+		},
+		"negation": {
+			query: "!test !^vendor !.md$",
+			want: searchQuery{
+				{{text: "test", kind: termExact, negate: true}},
+				{{text: "vendor", kind: termPrefix, negate: true}},
+				{{text: ".md", kind: termSuffix, negate: true}},
+			},
+		},
+		"or": {
+			query: "^core go$ | rb$ | py$",
+			want: searchQuery{
+				{{text: "core", kind: termPrefix}},
+				{{text: "go", kind: termSuffix}, {text: "rb", kind: termSuffix}, {text: "py", kind: termSuffix}},
+			},
+		},
+		"dangling_or": {
+			query: "| a |",
+			want:  searchQuery{{{text: "a"}}},
+		},
+		"path": {
+			query: "src/main",
+			want:  searchQuery{{{text: "src/main", path: true}}},
+		},
+		"escaped_space": {
+			query: `my\ file`,
+			want:  searchQuery{{{text: "my file"}}},
+		},
+		"incomplete_operators": {
+			query: "! ' ^ $ a",
+			want:  searchQuery{{{text: "a"}}},
+		},
+		"exact_keeps_dollar": {
+			query: "'a$",
+			want:  searchQuery{{{text: "a$", kind: termExact}}},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got := parseSearchQuery(test.query)
+			if !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected query %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchSearchQuery(t *testing.T) {
+	names := []string{"main.go", "main_test.go", "README.md", "Makefile", "model.go", "main.go"}
+	paths := []string{"main.go", "main_test.go", "README.md", "Makefile", "internal/model.go", "cmd/main.go"}
+
+	tests := map[string]struct {
+		query    string
+		strategy searchStrategy
+		want     []int
+	}{
+		"plain_uses_strategy": {
+			query:    "ma",
+			strategy: searchPrefix,
+			want:     []int{0, 1, 5},
+		},
+		"and": {
+			query:    "main test",
+			strategy: searchSubstring,
+			want:     []int{1},
+		},
+		"or": {
+			query:    ".md$ | Makefile",
+			strategy: searchPrefix,
+			want:     []int{2, 3},
+		},
+		"exact_smart_case": {
+			query:    "'READ",
+			strategy: searchPrefix,
+			want:     []int{2},
+		},
+		"exact_ignores_case": {
+			query:    "'readme",
+			strategy: searchPrefix,
+			want:     []int{2},
+		},
+		"prefix_and_suffix": {
+			query:    "^ma .go$",
+			strategy: searchPrefix,
+			want:     []int{0, 1, 5},
+		},
+		"equal": {
+			query:    "^main.go$",
+			strategy: searchPrefix,
+			want:     []int{0, 5},
+		},
+		"negation": {
+			query:    ".go$ !test",
+			strategy: searchPrefix,
+			want:     []int{0, 4, 5},
+		},
+		"only_negation": {
+			query:    "!.go",
+			strategy: searchPrefix,
+			want:     []int{2, 3},
+		},
+		"path": {
+			query:    "^cmd/",
+			strategy: searchPrefix,
+			want:     []int{5},
+		},
+		"fuzzy_path": {
+			query:    "intmod",
+			strategy: searchFuzzy,
+			want:     []int{},
+		},
+		"fuzzy_path_with_separator": {
+			query:    "int/mod",
+			strategy: searchFuzzy,
+			want:     []int{4},
+		},
+		"empty_query": {
+			query:    "!",
+			strategy: searchPrefix,
+			want:     []int{0, 1, 2, 3, 4, 5},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			matches := matchSearchQuery(parseSearchQuery(test.query), test.strategy, names, func() []string { return paths })
+			got := []int{}
+			for _, match := range matches {
+				got = append(got, match.index)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected indexes %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchSearchQueryRanking(t *testing.T) {
+	names := []string{"xmain.go", "main.go", "a-main.go"}
+
+	// Exact matches at the start of a name or word rank first, with ties in the order of the names.
+	matches := matchSearchQuery(parseSearchQuery("'main"), searchPrefix, names, nil)
+	got := []int{}
+	for _, match := range matches {
+		got = append(got, match.index)
+	}
+	if want := []int{1, 2, 0}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected indexes %v, got %v", want, got)
+	}
+
+	// A single plain term ranks like the strategy alone.
+	fuzzyNames := []string{"README.md", "main.go", "model.go"}
+	got = []int{}
+	for _, match := range matchSearchQuery(parseSearchQuery("mgo"), searchFuzzy, fuzzyNames, nil) {
+		got = append(got, match.index)
+	}
+	want := []int{}
+	for _, match := range searchNames("mgo", searchFuzzy, fuzzyNames) {
+		want = append(want, match.index)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected indexes %v, got %v", want, got)
+	}
+}
+
+func TestMatchSearchQueryHighlight(t *testing.T) {
+	names := []string{"main_test.go"}
+	paths := []string{"cmd/main_test.go"}
+
+	tests := map[string]struct {
+		query string
+		want  []int
+	}{
+		"terms_are_combined": {
+			query: "'main test",
+			want:  []int{0, 1, 2, 3, 5, 6, 7, 8},
+		},
+		"negation_is_not_highlighted": {
+			query: "^main !foo",
+			want:  []int{0, 1, 2, 3},
+		},
+		"path_offsets_within_name": {
+			query: "'cmd/ma",
+			want:  []int{0, 1},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			matches := matchSearchQuery(parseSearchQuery(test.query), searchSubstring, names, func() []string { return paths })
+			if len(matches) != 1 {
+				tt.Fatalf("expected 1 match, got %d", len(matches))
+			}
+			if !reflect.DeepEqual(matches[0].matched, test.want) {
+				tt.Fatalf("expected highlighted offsets %v, got %v", test.want, matches[0].matched)
+			}
+		})
+	}
+}
+
+func TestSearchPath(t *testing.T) {
+	root := filepath.Join("home", "user")
+	if got := searchPath(root, filepath.Join(root, "src", "main.go")); got != "src/main.go" {
+		t.Fatalf("expected relative path %q, got %q", "src/main.go", got)
+	}
+}
+
+func TestMatchSearchQueryPaths(t *testing.T) {
+	paths := []string{
+		"cmd/server/main.go",
+		"internal/serve/handler.go",
+		"docs/observer.md",
+		"serve.go",
+		"main.go",
+	}
+
+	tests := map[string]struct {
+		query string
+		want  []int
+	}{
+		"spans_directories": {
+			query: "cmd/serve",
+			want:  []int{0},
+		},
+		"prefers_name_matches": {
+			query: "serve",
+			want:  []int{3, 0, 1, 2},
+		},
+		"distinguishes_directories": {
+			query: "server/main",
+			want:  []int{0},
+		},
+		"operators": {
+			query: "^internal/ .go$",
+			want:  []int{1},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got := []int{}
+			for _, match := range matchSearchQueryPaths(parseSearchQuery(test.query), searchFuzzy, paths) {
+				got = append(got, match.index)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected indexes %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestFindTreeMatchesHighlight(t *testing.T) {
+	root := filepath.Join("home", "user")
+	nodes := []*treeNode{
+		{fullPath: filepath.Join(root, "cmd", "server", "main.go")},
+		{fullPath: root},
+	}
+	names := []string{"main.go", "user"}
+
+	tests := map[string]struct {
+		query      string
+		matchPaths bool
+		want       map[int][]int // Offsets in the relative paths by node.
+		wantName   map[int][]int // Offsets in the names by node, highlighted in the tree.
+	}{
+		"name": {
+			query:    "'main",
+			want:     map[int][]int{0: {11, 12, 13, 14}},
+			wantName: map[int][]int{0: {0, 1, 2, 3}},
+		},
+		"path_term_in_name": {
+			query:    "'server/ma",
+			want:     map[int][]int{0: {11, 12}},
+			wantName: map[int][]int{0: {0, 1}},
+		},
+		"paths": {
+			query:      "'server/ma",
+			matchPaths: true,
+			want:       map[int][]int{0: {4, 5, 6, 7, 8, 9, 10, 11, 12}},
+			wantName:   map[int][]int{0: {0, 1}},
+		},
+		"root_not_highlighted": {
+			query:    "'user",
+			want:     map[int][]int{1: nil},
+			wantName: map[int][]int{1: nil},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got, gotName := map[int][]int{}, map[int][]int{}
+			for _, match := range findTreeMatches(test.query, root, names, nodes, test.matchPaths) {
+				got[match.index] = match.matched
+				gotName[match.index] = nameOffsets(match.matched, searchPath(root, nodes[match.index].fullPath), names[match.index])
+				if len(gotName[match.index]) == 0 {
+					gotName[match.index] = nil
+				}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected path offsets %v, got %v", test.want, got)
+			}
+			if !reflect.DeepEqual(gotName, test.wantName) {
+				tt.Fatalf("expected name offsets %v, got %v", test.wantName, gotName)
+			}
+		})
+	}
+}
@@ -4,24 +4,49 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Colors are set by the active theme.
 var (
 	cursorRendererNormal         = newCursorRenderer(lipgloss.NewStyle().SetString(" "))
 	cursorRendererMarked         = newCursorRenderer(lipgloss.NewStyle().SetString("+"))
 	cursorRendererSelected       = newCursorRenderer(lipgloss.NewStyle().Bold(true).SetString(">"))
 	cursorRendererSelectedMarked = newCursorRenderer(lipgloss.NewStyle().Bold(true).SetString("+"))
 
-	barRendererLocation = lipgloss.NewStyle().Background(lipgloss.Color("#5C5C5C")).Foreground(lipgloss.Color("#FFFFFF"))
-	barRendererSearch   = lipgloss.NewStyle().Background(lipgloss.Color("#499F1C")).Foreground(lipgloss.Color("#FFFFFF"))
-	barRendererStatus   = lipgloss.NewStyle().Background(lipgloss.Color("#494949")).Foreground(lipgloss.Color("#FFFFFF"))
-	barRendererError    = lipgloss.NewStyle().Background(lipgloss.Color("#EB5B34")).Foreground(lipgloss.Color("#FFFFFF"))
-	barRendererOK       = lipgloss.NewStyle().Background(lipgloss.Color("#499F1C")).Foreground(lipgloss.Color("#FFFFFF"))
+	barRendererLocation = lipgloss.NewStyle()
+	barRendererSearch   = lipgloss.NewStyle()
+	barRendererStatus   = lipgloss.NewStyle()
+	barRendererError    = lipgloss.NewStyle()
+	barRendererOK       = lipgloss.NewStyle()
 
 	// Tree view breadcrumb styles
-	barRendererBreadcrumb          = lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA"))
-	barRendererBreadcrumbCurrent   = lipgloss.NewStyle().Bold(true).Background(lipgloss.Color("#499F1C")).Foreground(lipgloss.Color("#FFFFFF"))
-	barRendererBreadcrumbSeparator = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
-	barRendererScrollIndicator     = lipgloss.NewStyle().Foreground(lipgloss.Color("#666666")).Italic(true)
-	barRendererSearchCount         = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Italic(true)
+	barRendererBreadcrumb          = lipgloss.NewStyle()
+	barRendererBreadcrumbCurrent   = lipgloss.NewStyle().Bold(true)
+	barRendererBreadcrumbSeparator = lipgloss.NewStyle()
+	barRendererScrollIndicator     = lipgloss.NewStyle().Italic(true)
+	barRendererSearchCount         = lipgloss.NewStyle().Italic(true)
+
+	// Tab bar styles
+	tabRendererOther   = lipgloss.NewStyle()
+	tabRendererCurrent = lipgloss.NewStyle().Bold(true)
+
+	// Tree view line prefix style
+	treeRendererConnector = lipgloss.NewStyle()
+
+	// Preview pane separator style
+	previewRendererSeparator = lipgloss.NewStyle()
+
+	// Characters of entry names matched by a search
+	searchRendererMatch = lipgloss.NewStyle().Bold(true).Underline(true)
+	// searchRendererMatchPlain is not themed so that it remains uncolored with --no-color.
+	searchRendererMatchPlain = lipgloss.NewStyle().Bold(true).Underline(true)
+
+	// Git status marker and branch styles
+	gitRendererModified   = lipgloss.NewStyle()
+	gitRendererStaged     = lipgloss.NewStyle()
+	gitRendererUntracked  = lipgloss.NewStyle()
+	gitRendererIgnored    = lipgloss.NewStyle()
+	gitRendererConflicted = lipgloss.NewStyle().Bold(true)
+	gitRendererChanges    = lipgloss.NewStyle()
+	gitRendererBranch     = lipgloss.NewStyle()
 )
 
 type cursorRenderer struct {
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// searchStrategy selects how the search query matches entry names in normal mode.
+type searchStrategy int
+
+const (
+	searchPrefix    searchStrategy = iota // Names starting with the query.
+	searchSubstring                       // Names containing the query, ignoring case.
+	searchSmartCase                       // Names containing the query, ignoring case unless it contains an uppercase letter.
+	searchFuzzy                           // Names containing the characters of the query in order, best matches first.
+)
+
+var searchStrategyNames = []string{
+	searchPrefix:    "prefix",
+	searchSubstring: "substring",
+	searchSmartCase: "smart-case",
+	searchFuzzy:     "fuzzy",
+}
+
+func (s searchStrategy) String() string {
+	return searchStrategyNames[s]
+}
+
+// next returns the strategy following s, cycling back to the first.
+func (s searchStrategy) next() searchStrategy {
+	return (s + 1) % searchStrategy(len(searchStrategyNames))
+}
+
+func parseSearchStrategy(s string) (searchStrategy, error) {
+	for strategy, name := range searchStrategyNames {
+		if name == s {
+			return searchStrategy(strategy), nil
+		}
+	}
+	return searchPrefix, fmt.Errorf(
+		"invalid search strategy %q, expected one of %s", s, strings.Join(searchStrategyNames, ", "),
+	)
+}
+
+// searchMatch is an entry name matched by a search query.
+type searchMatch struct {
+	index   int   // Index of the name in the searched names.
+	matched []int // Byte offsets of the matched characters in the name.
+	score   int   // Quality of the match, which is only scored by fuzzy matching and query operators.
+}
+
+// searchNames returns the names matched by the query. Fuzzy matches are ordered by score and other
+// matches keep the order of the names.
+func searchNames(query string, strategy searchStrategy, names []string) []searchMatch {
+	matches := []searchMatch{}
+
+	if strategy == searchFuzzy {
+		for _, match := range fuzzy.Find(query, names) {
+			matches = append(matches, searchMatch{index: match.Index, matched: match.MatchedIndexes, score: match.Score})
+		}
+		return matches
+	}
+
+	ignoreCase := strategy == searchSubstring ||
+		(strategy == searchSmartCase && strings.ToLower(query) == query)
+
+	for i, name := range names {
+		var start, end int
+		switch {
+		case strategy == searchPrefix:
+			if !strings.HasPrefix(name, query) {
+				continue
+			}
+			start, end = 0, len(query)
+		case ignoreCase:
+			var found bool
+			if start, end, found = indexFold(name, query); !found {
+				continue
+			}
+		default:
+			if start = strings.Index(name, query); start < 0 {
+				continue
+			}
+			end = start + len(query)
+		}
+		matches = append(matches, searchMatch{index: i, matched: runeOffsets(name, start, end)})
+	}
+	return matches
+}
+
+// indexFold returns the byte range of the first occurrence of substr in s under Unicode case
+// folding.
+func indexFold(s string, substr string) (int, int, bool) {
+	for start := 0; start <= len(s); {
+		if end, ok := hasPrefixFold(s[start:], substr); ok {
+			return start, start + end, true
+		}
+		if start == len(s) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[start:])
+		start += size
+	}
+	return 0, 0, false
+}
+
+// hasPrefixFold reports whether s begins with prefix under Unicode case folding and returns the
+// length in bytes of the matching prefix of s.
+func hasPrefixFold(s string, prefix string) (int, bool) {
+	i := 0
+	for _, pr := range prefix {
+		if i >= len(s) {
+			return 0, false
+		}
+		sr, size := utf8.DecodeRuneInString(s[i:])
+		if !equalFoldRune(sr, pr) {
+			return 0, false
+		}
+		i += size
+	}
+	return i, true
+}
+
+func equalFoldRune(a rune, b rune) bool {
+	if a == b {
+		return true
+	}
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
+
+// runeOffsets returns the byte offsets of the runes of s within [start, end).
+func runeOffsets(s string, start int, end int) []int {
+	offsets := []int{}
+	for i := start; i < end; {
+		offsets = append(offsets, i)
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return offsets
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchNames(t *testing.T) {
+	names := []string{"README.md", "readme.txt", "main.go", "Makefile", "ÉCOLE", "école", "model.go"}
+
+	tests := map[string]struct {
+		query    string
+		strategy searchStrategy
+		want     []searchMatch
+	}{
+		"prefix": {
+			query:    "ma",
+			strategy: searchPrefix,
+			want:     []searchMatch{{index: 2, matched: []int{0, 1}}},
+		},
+		"prefix_case_sensitive": {
+			query:    "read",
+			strategy: searchPrefix,
+			want:     []searchMatch{{index: 1, matched: []int{0, 1, 2, 3}}},
+		},
+		"substring_ignores_case": {
+			query:    "ME",
+			strategy: searchSubstring,
+			want: []searchMatch{
+				{index: 0, matched: []int{4, 5}},
+				{index: 1, matched: []int{4, 5}},
+			},
+		},
+		"substring_unicode_fold": {
+			query:    "éc",
+			strategy: searchSubstring,
+			want: []searchMatch{
+				{index: 4, matched: []int{0, 2}},
+				{index: 5, matched: []int{0, 2}},
+			},
+		},
+		"smart_case_lowercase": {
+			query:    "make",
+			strategy: searchSmartCase,
+			want:     []searchMatch{{index: 3, matched: []int{0, 1, 2, 3}}},
+		},
+		"smart_case_uppercase": {
+			query:    "READ",
+			strategy: searchSmartCase,
+			want:     []searchMatch{{index: 0, matched: []int{0, 1, 2, 3}}},
+		},
+		"no_match": {
+			query:    "xyz",
+			strategy: searchSubstring,
+			want:     []searchMatch{},
+		},
+		"fuzzy_ordered_by_score": {
+			query:    "mgo",
+			strategy: searchFuzzy,
+			want: []searchMatch{
+				{index: 2, matched: []int{0, 5, 6}, score: 31},
+				{index: 6, matched: []int{0, 6, 7}, score: 30},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got := searchNames(test.query, test.strategy, names)
+			if !reflect.DeepEqual(got, test.want) {
+				tt.Fatalf("expected matches %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSearchStrategy(t *testing.T) {
+	for strategy := searchPrefix; strategy <= searchFuzzy; strategy++ {
+		got, err := parseSearchStrategy(strategy.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != strategy {
+			t.Fatalf("expected strategy %v, got %v", strategy, got)
+		}
+	}
+	if _, err := parseSearchStrategy("regex"); err == nil {
+		t.Fatal("expected error for unknown strategy")
+	}
+	if got := searchFuzzy.next(); got != searchPrefix {
+		t.Fatalf("expected strategy to cycle to %v, got %v", searchPrefix, got)
+	}
+}
+
+func TestHighlightNameBrackets(t *testing.T) {
+	tests := map[string]struct {
+		name      string
+		offsets   []int
+		want      string
+		wantAdded int
+	}{
+		"runs": {
+			name:      "go.mod.json",
+			offsets:   []int{0, 1, 3, 7},
+			want:      "[go].[m]od.[j]son",
+			wantAdded: 6,
+		},
+		"whole_name": {
+			name:      "abc",
+			offsets:   []int{0, 1, 2},
+			want:      "[abc]",
+			wantAdded: 2,
+		},
+		"multibyte": {
+			name:      "école",
+			offsets:   []int{0, 2},
+			want:      "[éc]ole",
+			wantAdded: 2,
+		},
+		"no_offsets": {
+			name:    "abc",
+			offsets: []int{},
+			want:    "abc",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			got, added := highlightName(test.name, test.offsets, highlightBrackets, "")
+			if got != test.want {
+				tt.Fatalf("expected %q, got %q", test.want, got)
+			}
+			if added != test.wantAdded {
+				tt.Fatalf("expected added width %d, got %d", test.wantAdded, added)
+			}
+		})
+	}
+}
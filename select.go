@@ -2,10 +2,10 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/dkaslovsky/nav/internal/sanitize"
 )
 
 func (m *model) selectAction() (*model, tea.Cmd) {
@@ -18,7 +18,7 @@ func (m *model) selectAction() (*model, tea.Cmd) {
 	m.saveCursor()
 
 	if selected.hasMode(entryModeFile) {
-		m.setExit(sanitize.SanitizeOutputPath(filepath.Join(m.path, selected.Name())))
+		m.setExit(filepath.Join(m.path, selected.Name()))
 		return m, tea.Quit
 	}
 	if selected.hasMode(entryModeSymlink) {
@@ -28,7 +28,7 @@ func (m *model) selectAction() (*model, tea.Cmd) {
 			return m, nil
 		}
 		// Return path for both files and directories
-		m.setExit(sanitize.SanitizeOutputPath(sl.absPath))
+		m.setExit(sl.absPath)
 		return m, tea.Quit
 	}
 	if selected.hasMode(entryModeDir) {
@@ -37,7 +37,7 @@ func (m *model) selectAction() (*model, tea.Cmd) {
 			m.setError(err, "failed to evaluate path")
 			return m, nil
 		}
-		m.setExit(sanitize.SanitizeOutputPath(path))
+		m.setExit(path)
 		return m, tea.Quit
 	}
 
@@ -61,7 +61,7 @@ func (m *model) searchSelectAction() (*model, tea.Cmd) {
 		m.saveCursor()
 
 		if node.entry.hasMode(entryModeFile) {
-			m.setExit(sanitize.SanitizeOutputPath(node.fullPath))
+			m.setExit(node.fullPath)
 			m.clearSearch()
 			return m, tea.Sequence(tea.ClearScreen, tea.Quit)
 		}
@@ -74,12 +74,12 @@ func (m *model) searchSelectAction() (*model, tea.Cmd) {
 				return m, nil
 			}
 			// Return path for both files and directories
-			m.setExit(sanitize.SanitizeOutputPath(sl.absPath))
+			m.setExit(sl.absPath)
 			m.clearSearch()
 			return m, tea.Sequence(tea.ClearScreen, tea.Quit)
 		}
 		if node.entry.hasMode(entryModeDir) {
-			m.setExit(sanitize.SanitizeOutputPath(node.fullPath))
+			m.setExit(node.fullPath)
 			m.clearSearch()
 			return m, tea.Sequence(tea.ClearScreen, tea.Quit)
 		}
@@ -100,7 +100,7 @@ func (m *model) searchSelectAction() (*model, tea.Cmd) {
 	}
 
 	if selected.hasMode(entryModeFile) {
-		m.setExit(sanitize.SanitizeOutputPath(filepath.Join(m.path, selected.Name())))
+		m.setExit(filepath.Join(m.path, selected.Name()))
 		return m, tea.Sequence(tea.ClearScreen, tea.Quit)
 	}
 	if selected.hasMode(entryModeSymlink) {
@@ -111,7 +111,7 @@ func (m *model) searchSelectAction() (*model, tea.Cmd) {
 			return m, nil
 		}
 		// Return path for both files and directories
-		m.setExit(sanitize.SanitizeOutputPath(sl.absPath))
+		m.setExit(sl.absPath)
 		m.clearSearch()
 		return m, tea.Sequence(tea.ClearScreen, tea.Quit)
 	}
@@ -122,7 +122,7 @@ func (m *model) searchSelectAction() (*model, tea.Cmd) {
 			m.clearSearch()
 			return m, nil
 		}
-		m.setExit(sanitize.SanitizeOutputPath(path))
+		m.setExit(path)
 		m.clearSearch()
 		return m, tea.Sequence(tea.ClearScreen, tea.Quit)
 	}
@@ -134,3 +134,47 @@ func (m *model) searchSelectAction() (*model, tea.Cmd) {
 	m.clearSearch()
 	return m, nil
 }
+
+// selectedPaths returns the paths of the marked entries, or of the entry under the cursor when no
+// entries are marked, with symlinks resolved to their targets.
+func (m *model) selectedPaths() ([]string, error) {
+	type selection struct {
+		dir string
+		ent *entry
+	}
+	selections := []selection{}
+
+	switch {
+	case m.modeMarks:
+		marks := m.markSet()
+		for _, path := range m.markedPaths() {
+			selections = append(selections, selection{dir: filepath.Dir(path), ent: marks[path]})
+		}
+	case m.modeTree:
+		node := m.selectedTreeNode()
+		if node == nil || node.entry == nil {
+			return nil, errors.New("no entry under the cursor")
+		}
+		selections = append(selections, selection{dir: filepath.Dir(node.fullPath), ent: node.entry})
+	default:
+		selected, err := m.selected()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection{dir: m.path, ent: selected})
+	}
+
+	paths := make([]string, 0, len(selections))
+	for _, s := range selections {
+		if s.ent.hasMode(entryModeSymlink) {
+			sl, err := followSymlink(s.dir, s.ent)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate symlink: %w", err)
+			}
+			paths = append(paths, sl.absPath)
+			continue
+		}
+		paths = append(paths, filepath.Join(s.dir, s.ent.Name()))
+	}
+	return paths, nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tab is the state owned by each tab: the directory shown and its history, the view mode, the
+// search, marks, and cursors within it, and the other pane in dual-pane mode. In dual-pane mode each
+// pane has its own tab state, and the focused pane holds the tab. The model embeds the current tab.
+type tab struct {
+	path        string
+	prevPath    string
+	history     history // Back and forward history of the tab.
+	prevHistory history // History before the last setPath, restored with the previous path.
+	entries     []*entry
+	displayed   int
+	search      string
+	pathCache   map[string]*cacheItem // Map path to cached state.
+	basket      map[string]*entry     // Map full path to entry for entries marked in the grid.
+	treeMarks   map[string]*entry     // Map full path to entry for marked tree nodes.
+
+	c       int // Cursor column position.
+	r       int // Cursor row position.
+	columns int // Displayed columns.
+	rows    int // Displayed columns.
+
+	modeMarks  bool
+	modeSearch bool
+	modeTree   bool
+
+	// Dual-pane fields, held by the focused pane of a tab.
+	modeDualPane bool
+	otherPane    *tab // Pane that is not focused in dual-pane mode.
+	paneRight    bool // The focused pane is the right pane in dual-pane mode.
+
+	// Tree mode fields
+	treeRoot     *treeNode
+	visibleNodes []*treeNode
+	treeIdx      int
+	scrollOffset int
+	// treeLastChild maps parent directory path to the name of the last selected child.
+	// Used to restore cursor position when re-expanding a previously collapsed directory.
+	// Path-based (not pointer-based) so it survives tree node recreation during navigation.
+	treeLastChild map[string]string
+	// treeSearchStartNode is the node at cursor position when search mode is entered.
+	// Search will be scoped to this node's subtree (or its parent's subtree if it's a file).
+	treeSearchStartNode *treeNode
+	// searchMatchNodes stores the actual fuzzy match results (not ancestors) for returning on Enter
+	searchMatchNodes []*treeNode
+	// searchHighlights stores the byte offsets of the characters of each matched node's path
+	// relative to the search root that matched the search, for highlighting.
+	searchHighlights map[*treeNode][]int
+	// searchHiddenCursor stores the cursor of the search result presentation (filtered tree or flat
+	// list) that is not shown.
+	searchHiddenCursor treeCursor
+}
+
+func newTab() *tab {
+	return &tab{
+		pathCache: make(map[string]*cacheItem),
+		basket:    make(map[string]*entry),
+		treeMarks: make(map[string]*entry),
+
+		modeMarks:  false,
+		modeSearch: false,
+		modeTree:   false,
+
+		modeDualPane: false,
+
+		treeIdx:             0,
+		scrollOffset:        0,
+		treeLastChild:       make(map[string]string),
+		treeSearchStartNode: nil,
+	}
+}
+
+// resize sets the size available to the views of a pane, which shrink by the tab bar when it is
+// shown and share the width of the screen in dual-pane mode.
+func (m *model) resize() {
+	m.height = m.screenHeight
+	if len(m.tabs) > 1 {
+		m.height--
+	}
+	m.width = m.screenWidth
+	if m.modeDualPane {
+		m.width = (m.screenWidth - lipgloss.Width(paneSeparator)) / 2
+	}
+	if m.modeTree {
+		m.adjustScrollOffset()
+	}
+}
+
+// openTab opens a new tab after the current tab, showing the current directory in the current view
+// mode, and switches to it.
+func (m *model) openTab() tea.Cmd {
+	t := newTab()
+	t.path = m.path
+	t.modeTree = m.modeTree
+
+	m.tabs = append(m.tabs[:m.tabIdx+1], append([]*tab{t}, m.tabs[m.tabIdx+1:]...)...)
+	return m.switchTab(m.tabIdx + 1)
+}
+
+// closeTab closes the current tab and switches to the tab before it. The last tab is never closed.
+func (m *model) closeTab() tea.Cmd {
+	if len(m.tabs) <= 1 {
+		return nil
+	}
+	m.clearSearch()
+	m.tabs = append(m.tabs[:m.tabIdx], m.tabs[m.tabIdx+1:]...)
+	idx := max(m.tabIdx-1, 0)
+	m.tabIdx = -1 // The closed tab is not saved.
+	return m.switchTab(idx)
+}
+
+// cycleTab switches to the tab delta positions after the current tab, wrapping around.
+func (m *model) cycleTab(delta int) tea.Cmd {
+	n := len(m.tabs)
+	return m.switchTab(((m.tabIdx+delta)%n + n) % n)
+}
+
+// moveTab moves the current tab delta positions in the tab bar.
+func (m *model) moveTab(delta int) {
+	idx := m.tabIdx + delta
+	if idx < 0 || idx >= len(m.tabs) {
+		return
+	}
+	m.tabs[m.tabIdx], m.tabs[idx] = m.tabs[idx], m.tabs[m.tabIdx]
+	m.tabIdx = idx
+}
+
+// switchTab makes the tab at idx current.
+func (m *model) switchTab(idx int) tea.Cmd {
+	if idx == m.tabIdx {
+		return nil
+	}
+	if m.tabIdx >= 0 {
+		m.saveCursor()
+	}
+	m.tabIdx = idx
+	m.tab = m.tabs[idx]
+	return m.activateTab()
+}
+
+// activateTab prepares the tab that was made current. The search index covers a single tree, so it
+// is rebuilt for the tab, and tabs that have not been shown yet are listed.
+func (m *model) activateTab() tea.Cmd {
+	m.stopSearchWorker()
+	m.stopSearchIndexLoader()
+	m.searchIndexNodes = nil
+	m.searchIndexNames = nil
+	m.searchIndexRoot = nil
+	m.searchPendingMatches = nil
+	m.resize()
+	m.syncWatches()
+
+	if m.modeTree {
+		if m.treeRoot == nil {
+			err, cmd := m.listTree()
+			if err != nil {
+				m.setError(err, "failed to read directory")
+			}
+			return cmd
+		}
+		return m.startSearchIndexLoader(m.treeRoot)
+	}
+	if m.entries == nil {
+		if err := m.list(); err != nil {
+			m.setError(err, "failed to read directory")
+		}
+	}
+	return nil
+}
+
+// tabBar renders the tabs, numbered and named by their directories, with the current tab
+// highlighted. It is only shown when more than one tab is open.
+func (m *model) tabBar() string {
+	tabs := make([]string, len(m.tabs))
+	for i, t := range m.tabs {
+		name := filepath.Base(t.path)
+		if name == "" || name == "." {
+			name = t.path
+		}
+		tab := fmt.Sprintf(" %d:%s ", i+1, sanitizePreviewLine(name))
+		if i == m.tabIdx {
+			tabs[i] = tabRendererCurrent.Render(tab)
+		} else {
+			tabs[i] = tabRendererOther.Render(tab)
+		}
+	}
+	return lipgloss.NewStyle().MaxWidth(m.screenWidth).Render(strings.Join(tabs, " "))
+}
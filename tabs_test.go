@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTabs(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+
+	m := newModel()
+	m.path = dir
+	if err := m.list(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new tab starts in the current directory and shrinks the views by the tab bar.
+	m.openTab()
+	if len(m.tabs) != 2 || m.tabIdx != 1 || m.path != dir {
+		t.Fatalf("expected second tab at %s, got tab %d of %d at %s", dir, m.tabIdx, len(m.tabs), m.path)
+	}
+	if m.height != m.screenHeight-1 {
+		t.Fatalf("expected height %d, got %d", m.screenHeight-1, m.height)
+	}
+
+	// Each tab keeps its own directory, view mode, and marks.
+	m.jumpTo(a)
+	loadTestTree(t, m, a)
+	m.cycleTab(1)
+	if m.tabIdx != 0 || m.path != dir || m.modeTree {
+		t.Fatalf("expected first tab at %s in grid mode, got tab %d at %s", dir, m.tabIdx, m.path)
+	}
+	m.basket[a] = m.entries[0]
+	m.cycleTab(-1)
+	if m.path != a || !m.modeTree || len(m.basket) != 0 {
+		t.Fatalf("expected second tab at %s in tree mode without marks, got %s", a, m.path)
+	}
+
+	// Moving a tab keeps it current.
+	m.moveTab(-1)
+	if m.tabIdx != 0 || m.tabs[0].path != a || m.tabs[1].path != dir {
+		t.Fatalf("expected tabs [%s %s] at 0, got [%s %s] at %d", a, dir, m.tabs[0].path, m.tabs[1].path, m.tabIdx)
+	}
+	m.moveTab(-1)
+	if m.tabIdx != 0 {
+		t.Fatalf("expected the first tab to stay first, got %d", m.tabIdx)
+	}
+
+	// Closing a tab switches to the neighboring tab, and the last tab is never closed.
+	m.openTab()
+	m.jumpTo(b)
+	m.closeTab()
+	if len(m.tabs) != 2 || m.tabIdx != 0 || m.path != a {
+		t.Fatalf("expected first of 2 tabs at %s, got tab %d of %d at %s", a, m.tabIdx, len(m.tabs), m.path)
+	}
+	m.closeTab()
+	m.closeTab()
+	if len(m.tabs) != 1 || m.path != dir || m.height != m.screenHeight {
+		t.Fatalf("expected a single tab at %s with full height, got %d tabs at %s", dir, len(m.tabs), m.path)
+	}
+}
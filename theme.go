@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/dkaslovsky/nav/internal/xdg"
+)
+
+const (
+	themeDark  = "dark"
+	themeLight = "light"
+
+	themeDirName = "themes"
+	themeExt     = ".toml"
+)
+
+// themeElement associates a themeable interface element with the styles it colors.
+type themeElement struct {
+	name   string
+	styles []*lipgloss.Style
+}
+
+var themeElements = []*themeElement{
+	{name: "location", styles: []*lipgloss.Style{&barRendererLocation}},
+	{name: "search", styles: []*lipgloss.Style{&barRendererSearch}},
+	{name: "status", styles: []*lipgloss.Style{&barRendererStatus}},
+	{name: "error", styles: []*lipgloss.Style{&barRendererError}},
+	{name: "ok", styles: []*lipgloss.Style{&barRendererOK}},
+	{name: "breadcrumb", styles: []*lipgloss.Style{&barRendererBreadcrumb}},
+	{name: "breadcrumb-current", styles: []*lipgloss.Style{&barRendererBreadcrumbCurrent}},
+	{name: "breadcrumb-separator", styles: []*lipgloss.Style{&barRendererBreadcrumbSeparator}},
+	{name: "scroll-indicator", styles: []*lipgloss.Style{&barRendererScrollIndicator}},
+	{name: "search-count", styles: []*lipgloss.Style{&barRendererSearchCount}},
+	{name: "tab", styles: []*lipgloss.Style{&tabRendererOther}},
+	{name: "tab-current", styles: []*lipgloss.Style{&tabRendererCurrent}},
+	{name: "cursor", styles: []*lipgloss.Style{&cursorRendererSelected.style, &cursorRendererSelectedMarked.style}},
+	{name: "marked", styles: []*lipgloss.Style{&cursorRendererMarked.style}},
+	{name: "tree-connector", styles: []*lipgloss.Style{&treeRendererConnector}},
+	{name: "preview-separator", styles: []*lipgloss.Style{&previewRendererSeparator}},
+	{name: "search-match", styles: []*lipgloss.Style{&searchRendererMatch}},
+	{name: "git-modified", styles: []*lipgloss.Style{&gitRendererModified}},
+	{name: "git-staged", styles: []*lipgloss.Style{&gitRendererStaged}},
+	{name: "git-untracked", styles: []*lipgloss.Style{&gitRendererUntracked}},
+	{name: "git-ignored", styles: []*lipgloss.Style{&gitRendererIgnored}},
+	{name: "git-conflicted", styles: []*lipgloss.Style{&gitRendererConflicted}},
+	{name: "git-changes", styles: []*lipgloss.Style{&gitRendererChanges}},
+	{name: "git-branch", styles: []*lipgloss.Style{&gitRendererBranch}},
+}
+
+// themeColors holds the foreground and background colors of an element. Colors are hex values
+// ("#5C5C5C") or ANSI color numbers ("0" through "255"); empty values use the terminal default.
+// Colors are degraded to the palette supported by the terminal when rendered.
+type themeColors struct {
+	fg string
+	bg string
+}
+
+// theme defines the colors of interface elements and the default colors of entries.
+type theme struct {
+	elements map[string]themeColors
+	entries  map[string]string // SGR parameters keyed by LS_COLORS type code.
+}
+
+func builtinTheme(name string) (*theme, bool) {
+	switch name {
+	case themeDark:
+		return &theme{
+			elements: map[string]themeColors{
+				"location":             {fg: "#FFFFFF", bg: "#5C5C5C"},
+				"search":               {fg: "#FFFFFF", bg: "#499F1C"},
+				"status":               {fg: "#FFFFFF", bg: "#494949"},
+				"error":                {fg: "#FFFFFF", bg: "#EB5B34"},
+				"ok":                   {fg: "#FFFFFF", bg: "#499F1C"},
+				"breadcrumb":           {fg: "#AAAAAA"},
+				"breadcrumb-current":   {fg: "#FFFFFF", bg: "#499F1C"},
+				"breadcrumb-separator": {fg: "#888888"},
+				"scroll-indicator":     {fg: "#666666"},
+				"search-count":         {fg: "#888888"},
+				"tab":                  {fg: "#AAAAAA", bg: "#3A3A3A"},
+				"tab-current":          {fg: "#FFFFFF", bg: "#5C5C5C"},
+				"preview-separator":    {fg: "#666666"},
+				"search-match":         {fg: "#FFD75F"},
+				"git-modified":         {fg: "#E5C07B"},
+				"git-staged":           {fg: "#98C379"},
+				"git-untracked":        {fg: "#E06C75"},
+				"git-ignored":          {fg: "#666666"},
+				"git-conflicted":       {fg: "#FF5F5F"},
+				"git-changes":          {fg: "#E5C07B"},
+				"git-branch":           {fg: "#FFFFFF", bg: "#6C4FA3"},
+			},
+			entries: map[string]string{
+				"fi":             "37",
+				"di":             "36",
+				"ln":             "35",
+				"ex":             "32",
+				entryColorHidden: "33",
+			},
+		}, true
+	case themeLight:
+		return &theme{
+			elements: map[string]themeColors{
+				"location":             {fg: "#1C1C1C", bg: "#D0D0D0"},
+				"search":               {fg: "#FFFFFF", bg: "#3A7A12"},
+				"status":               {fg: "#1C1C1C", bg: "#E4E4E4"},
+				"error":                {fg: "#FFFFFF", bg: "#C0392B"},
+				"ok":                   {fg: "#FFFFFF", bg: "#3A7A12"},
+				"breadcrumb":           {fg: "#444444"},
+				"breadcrumb-current":   {fg: "#FFFFFF", bg: "#3A7A12"},
+				"breadcrumb-separator": {fg: "#666666"},
+				"scroll-indicator":     {fg: "#6C6C6C"},
+				"search-count":         {fg: "#555555"},
+				"tab":                  {fg: "#444444", bg: "#EEEEEE"},
+				"tab-current":          {fg: "#1C1C1C", bg: "#D0D0D0"},
+				"tree-connector":       {fg: "#8A8A8A"},
+				"preview-separator":    {fg: "#8A8A8A"},
+				"search-match":         {fg: "#AF5F00"},
+				"git-modified":         {fg: "#B58900"},
+				"git-staged":           {fg: "#3A7A12"},
+				"git-untracked":        {fg: "#C0392B"},
+				"git-ignored":          {fg: "#8A8A8A"},
+				"git-conflicted":       {fg: "#D70000"},
+				"git-changes":          {fg: "#B58900"},
+				"git-branch":           {fg: "#FFFFFF", bg: "#7B5EA7"},
+			},
+			entries: map[string]string{
+				"fi":             "0",
+				"di":             "34",
+				"ln":             "35",
+				"ex":             "32",
+				entryColorHidden: "90",
+			},
+		}, true
+	}
+	return nil, false
+}
+
+// readTheme returns a built-in theme or a theme read from a file. A name that is not a path is
+// resolved to $XDG_CONFIG_HOME/nav/themes/<name>.toml. Theme files use the config file format:
+//
+//	base = "light"  # optional built-in theme to extend, defaults to dark
+//
+//	[location]
+//	fg = "#000000"
+//	bg = "#D0D0D0"
+//
+//	[entries]
+//	di = "01;34"
+func readTheme(themeName string) (*theme, error) {
+	if t, ok := builtinTheme(themeName); ok {
+		return t, nil
+	}
+
+	path := themeName
+	if !strings.ContainsRune(themeName, filepath.Separator) && filepath.Ext(themeName) != themeExt {
+		dir, err := xdg.ConfigHome()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, name, themeDirName, themeName+themeExt)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load theme %q: %w", themeName, err)
+	}
+	defer f.Close()
+
+	values, err := parseConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+
+	t, _ := builtinTheme(themeDark)
+	for _, v := range values {
+		if v.section != "" || v.key != "base" {
+			continue
+		}
+		base, err := v.asString()
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, v.line, err)
+		}
+		var ok bool
+		if t, ok = builtinTheme(base); !ok {
+			return nil, fmt.Errorf("%s:%d: unknown base theme %q", path, v.line, base)
+		}
+	}
+	for _, v := range values {
+		if err := t.set(v); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, v.line, err)
+		}
+	}
+	return t, nil
+}
+
+// set applies a value read from a theme file.
+func (t *theme) set(v *configValue) error {
+	switch {
+	case v.section == "" && v.key == "base":
+		return nil
+	case v.section == "entries":
+		sgr, err := v.asString()
+		if err != nil {
+			return err
+		}
+		t.entries[v.key] = sgr
+		return nil
+	}
+
+	known := false
+	for _, el := range themeElements {
+		if el.name == v.section {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown key %q", v.name())
+	}
+
+	var c string
+	switch val := v.value.(type) {
+	case string:
+		c = val
+	case int64:
+		c = strconv.FormatInt(val, 10)
+	}
+	if !validThemeColor(c) {
+		return fmt.Errorf("%q must be a hex color or an ANSI color number", v.name())
+	}
+
+	colors := t.elements[v.section]
+	switch v.key {
+	case "fg":
+		colors.fg = c
+	case "bg":
+		colors.bg = c
+	default:
+		return fmt.Errorf("unknown key %q", v.name())
+	}
+	t.elements[v.section] = colors
+	return nil
+}
+
+// apply sets the interface styles from the theme.
+func (t *theme) apply() {
+	for _, el := range themeElements {
+		colors := t.elements[el.name]
+		for _, style := range el.styles {
+			s := style.UnsetForeground().UnsetBackground()
+			if colors.fg != "" {
+				s = s.Foreground(lipgloss.Color(colors.fg))
+			}
+			if colors.bg != "" {
+				s = s.Background(lipgloss.Color(colors.bg))
+			}
+			*style = s
+		}
+	}
+}
+
+// entryColors returns the theme's entry coloring rules.
+func (t *theme) entryColors() *entryColors {
+	ec := newEntryColors()
+	for k, sgr := range t.entries {
+		ec.setType(k, sgr)
+	}
+	return ec
+}
+
+// defaultEntryColors returns the entry coloring rules of the default theme.
+func defaultEntryColors() *entryColors {
+	t, _ := builtinTheme(themeDark)
+	return t.entryColors()
+}
+
+// loadTheme applies the configured theme and sets the entry coloring rules, preferring LS_COLORS
+// when it is set and enabled. Entry colors are degraded for the terminal's color profile.
+func (m *model) loadTheme(profile termenv.Profile) error {
+	t, err := readTheme(m.theme)
+	if err != nil {
+		return err
+	}
+	t.apply()
+
+	colors := t.entryColors()
+	if lsColors := os.Getenv(envLSColors); m.modeLSColors && lsColors != "" {
+		colors = parseLSColors(lsColors)
+	}
+	colors.degrade(profile)
+	m.entryColors = colors
+	return nil
+}
+
+func validThemeColor(c string) bool {
+	if strings.HasPrefix(c, "#") {
+		if len(c) != 4 && len(c) != 7 {
+			return false
+		}
+		_, err := strconv.ParseUint(c[1:], 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && 0 <= n && n <= 255
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadTheme(t *testing.T) {
+	tests := map[string]struct {
+		theme   string
+		check   func(*theme) bool
+		wantErr string
+	}{
+		"extends_dark": {
+			theme: "[location]\nfg = \"#000000\"\nbg = 252\n\n[entries]\ndi = \"01;34\"",
+			check: func(t *theme) bool {
+				return t.elements["location"] == themeColors{fg: "#000000", bg: "252"} &&
+					t.elements["search"].bg == "#499F1C" && t.entries["di"] == "01;34" && t.entries["ln"] == "35"
+			},
+		},
+		"base": {
+			theme: "base = \"light\"\n[ok]\nfg = \"#FFF\"",
+			check: func(t *theme) bool {
+				return t.elements["ok"] == themeColors{fg: "#FFF", bg: "#3A7A12"} && t.entries["di"] == "34"
+			},
+		},
+		"unknown_base": {
+			theme:   "base = \"solarized\"",
+			wantErr: `:1: unknown base theme "solarized"`,
+		},
+		"unknown_element": {
+			theme:   "[locaton]\nfg = \"#000000\"",
+			wantErr: `:2: unknown key "locaton.fg"`,
+		},
+		"unknown_color_key": {
+			theme:   "[location]\ncolor = \"#000000\"",
+			wantErr: `:2: unknown key "location.color"`,
+		},
+		"invalid_color": {
+			theme:   "[location]\nfg = \"#00000\"",
+			wantErr: `:2: "location.fg" must be a hex color or an ANSI color number`,
+		},
+		"color_out_of_range": {
+			theme:   "[location]\nbg = 256",
+			wantErr: `:2: "location.bg" must be a hex color or an ANSI color number`,
+		},
+		"entry_not_string": {
+			theme:   "[entries]\ndi = 34",
+			wantErr: `:2: "entries.di" must be a string`,
+		},
+		"syntax_error": {
+			theme:   "[location",
+			wantErr: ":1: unterminated section header",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(tt *testing.T) {
+			path := filepath.Join(tt.TempDir(), "custom"+themeExt)
+			if err := os.WriteFile(path, []byte(test.theme), 0o600); err != nil {
+				tt.Fatal(err)
+			}
+			got, err := readTheme(path)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != path+test.wantErr {
+					tt.Fatalf("expected error %q, got %v", path+test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("unexpected error: %v", err)
+			}
+			if !test.check(got) {
+				tt.Fatalf("theme values not applied: %+v", got)
+			}
+		})
+	}
+}
+
+func TestReadThemeByName(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if theme, err := readTheme(themeLight); err != nil || theme.entries["di"] != "34" {
+		t.Fatalf("expected the built-in light theme, got %v", err)
+	}
+
+	// A missing theme is reported by name.
+	_, err := readTheme("mine")
+	if err == nil || !strings.Contains(err.Error(), `failed to load theme "mine"`) {
+		t.Fatalf("expected a missing theme error, got %v", err)
+	}
+
+	path := filepath.Join(dir, name, themeDirName, "mine"+themeExt)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("[entries]\nln = \"target\""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	theme, err := readTheme("mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if colors := theme.entryColors(); !colors.linkTarget {
+		t.Fatal("expected the theme to color symlinks as their targets")
+	}
+}
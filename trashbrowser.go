@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/dkaslovsky/nav/internal/trash"
+)
+
+// trashDateLayout is the format of deletion dates in the trash browser.
+const trashDateLayout = "2006-01-02 15:04"
+
+// trashBrowser lists the items of the home trash and of the trash for the current directory's
+// filesystem, most recently deleted first.
+type trashBrowser struct {
+	listView
+	items []*trash.Item
+}
+
+func (m *model) openTrash() {
+	m.modeTrash = true
+	m.trash = &trashBrowser{listView: listView{title: " Trash"}}
+	if err := m.loadTrash(); err != nil {
+		m.setError(err, "failed to load trash")
+	}
+}
+
+func (m *model) closeTrash() {
+	m.modeTrash = false
+	m.trash = nil
+}
+
+func (m *model) loadTrash() error {
+	home, err := trash.Home()
+	if err != nil {
+		return err
+	}
+	trashes := []*trash.Trash{home}
+	if t, err := trash.ForPath(m.path); err == nil && t.Dir != home.Dir {
+		trashes = append(trashes, t)
+	}
+
+	items := []*trash.Item{}
+	for _, t := range trashes {
+		trashItems, err := t.List()
+		if err != nil {
+			return err
+		}
+		items = append(items, trashItems...)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletionDate.After(items[j].DeletionDate)
+	})
+
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = fmt.Sprintf("%s    %s", item.DeletionDate.Format(trashDateLayout), sanitizePreviewLine(substituteHomeDir(item.Path)))
+	}
+	m.trash.items = items
+	m.trash.setLines(lines)
+	return nil
+}
+
+func (m *model) selectedTrashItem() (*trash.Item, bool) {
+	if m.trash == nil || m.trash.idx >= len(m.trash.items) {
+		return nil, false
+	}
+	return m.trash.items[m.trash.idx], true
+}
+
+// restoreTrashItem moves the selected item back to its original path.
+func (m *model) restoreTrashItem() tea.Cmd {
+	item, ok := m.selectedTrashItem()
+	if !ok {
+		return nil
+	}
+	err := item.Trash.Restore(item)
+	cmd := m.refresh(item.Path)
+	if err != nil {
+		m.setError(err, "failed to restore entry")
+	}
+	return cmd
+}
+
+// promptPurge asks for confirmation before permanently deleting the selected item.
+func (m *model) promptPurge() {
+	item, ok := m.selectedTrashItem()
+	if !ok {
+		return
+	}
+	m.setPrompt(&prompt{
+		label:   fmt.Sprintf("permanently delete %s", filepath.Base(item.Path)),
+		confirm: true,
+		action: func(m *model, _ string) (string, error) {
+			return "", item.Trash.Purge(item)
+		},
+		status: "failed to delete entry",
+	})
+}
+
+func actionModeTrash(m *model, msg tea.KeyMsg, esc bool) actionResult {
+	switch {
+
+	case esc || key.Matches(msg, keyEsc) || key.Matches(msg, keyModeTrash):
+		m.closeTrash()
+
+	case key.Matches(msg, keyUp):
+		m.trash.moveUp()
+
+	case key.Matches(msg, keyDown):
+		m.trash.moveDown()
+
+	case key.Matches(msg, keyRestore):
+		return newActionResult(m.restoreTrashItem())
+
+	case key.Matches(msg, keyDelete):
+		m.promptPurge()
+
+	}
+
+	// Unconditional return to disable all other functionality.
+	return newActionResult(m.indexingCmd())
+}
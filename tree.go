@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/dkaslovsky/nav/internal/gitignore"
 )
 
 const searchBatchSize = 500 // Nodes per batch
@@ -17,6 +21,29 @@ type treeNode struct {
 	depth    int
 	loaded   bool
 	fullPath string
+	stale    bool // The file info of the entry is from the search index cache and may be out of date.
+
+	// ignore matches the children of the node against the ignore rules of the git repository
+	// containing it, if any.
+	ignore  *gitignore.Matcher
+	ignored bool // Ignored by git, either directly or through an ignored ancestor.
+}
+
+// nodeFilter selects the nodes that are shown in the tree and indexed for search.
+type nodeFilter struct {
+	hidden  bool // Include hidden entries.
+	ignored bool // Include entries ignored by git.
+}
+
+// skip returns true if the node is excluded by the filter. The virtual root is never excluded.
+func (f nodeFilter) skip(n *treeNode) bool {
+	if n.entry == nil {
+		return false
+	}
+	if !f.hidden && n.entry.hasMode(entryModeHidden) {
+		return true
+	}
+	return !f.ignored && n.ignored
 }
 
 func newTreeNode(ent *entry, parent *treeNode, basePath string) *treeNode {
@@ -33,7 +60,7 @@ func newTreeNode(ent *entry, parent *treeNode, basePath string) *treeNode {
 }
 
 // loadChildren populates children lazily when node is expanded
-func (n *treeNode) loadChildren() error {
+func (n *treeNode) loadChildren(order sortOrder) error {
 	if n.loaded || !n.entry.hasMode(entryModeDir) {
 		return nil
 	}
@@ -51,18 +78,179 @@ func (n *treeNode) loadChildren() error {
 		}
 		entries = append(entries, ent)
 	}
-	sortEntries(entries)
+	n.setChildren(entries, order)
+	return nil
+}
+
+// setChildren populates the children of the node with the entries of its directory.
+func (n *treeNode) setChildren(entries []*entry, order sortOrder) {
+	sortEntries(entries, order)
 
+	ignore := n.childIgnore(entries)
 	n.children = make([]*treeNode, 0, len(entries))
 	for _, ent := range entries {
-		n.children = append(n.children, newTreeNode(ent, n, n.fullPath))
+		n.children = append(n.children, n.newChild(ent, ignore))
 	}
 	n.loaded = true
+}
+
+// childIgnore returns the matcher for the children of the directory with the given entries, which
+// is a new matcher if the directory is the root of a git repository.
+func (n *treeNode) childIgnore(entries []*entry) *gitignore.Matcher {
+	for _, ent := range entries {
+		if ent.Name() == gitignore.GitDir {
+			return gitignore.New(n.fullPath)
+		}
+	}
+	return n.ignore
+}
+
+// newChild creates a child node for an entry of the directory, marking it if it is ignored by git.
+func (n *treeNode) newChild(ent *entry, ignore *gitignore.Matcher) *treeNode {
+	child := newTreeNode(ent, n, n.fullPath)
+	child.ignore = ignore
+	child.ignored = n.ignored || ignore.Match(child.fullPath, ent.hasMode(entryModeDir))
+	return child
+}
+
+// reload re-reads the children of all loaded directories in the subtree. Children that still exist
+// keep their state so that expanded directories remain expanded.
+func (n *treeNode) reload(order sortOrder) error {
+	if _, _, err := n.reloadChildren(order); err != nil {
+		return err
+	}
+	for _, child := range n.children {
+		_ = child.reload(order) // Ignore errors
+	}
 	return nil
 }
 
+// reloadChildren re-reads the children of a loaded directory and returns the children that were
+// added and removed. Children that still exist keep their state and their own children.
+func (n *treeNode) reloadChildren(order sortOrder) (added []*treeNode, removed []*treeNode, err error) {
+	if n == nil || !n.loaded {
+		return nil, nil, nil
+	}
+
+	files, err := os.ReadDir(n.fullPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing := make(map[string]*treeNode, len(n.children))
+	for _, child := range n.children {
+		existing[child.entry.Name()] = child
+	}
+
+	entries := make([]*entry, 0, len(files))
+	for _, f := range files {
+		ent, err := newEntry(f)
+		if err != nil {
+			continue // skip unreadable entries
+		}
+		entries = append(entries, ent)
+	}
+	sortEntries(entries, order)
+
+	ignore := n.childIgnore(entries)
+	n.children = make([]*treeNode, 0, len(entries))
+	for _, ent := range entries {
+		child, ok := existing[ent.Name()]
+		if !ok || child.entry.mode != ent.mode {
+			child = n.newChild(ent, ignore)
+			added = append(added, child)
+		} else {
+			delete(existing, ent.Name())
+		}
+		child.entry = ent
+		child.stale = false
+		n.children = append(n.children, child)
+	}
+	for _, child := range existing {
+		removed = append(removed, child)
+	}
+	return added, removed, nil
+}
+
+// refreshInfo reads the file info of a node listed from the search index cache, so that it is
+// displayed and sorted by its current size and times.
+func (n *treeNode) refreshInfo() {
+	if !n.stale {
+		return
+	}
+	n.stale = false
+	info, err := os.Lstat(n.fullPath)
+	if err != nil {
+		// The entry was removed, which its directory reports once it is validated.
+		return
+	}
+	if ent, err := newEntry(fs.FileInfoToDirEntry(info)); err == nil && ent.mode == n.entry.mode {
+		n.entry = ent
+	}
+}
+
+// refreshInfos refreshes the file infos of the children of the directory and of its expanded
+// subdirectories, which are displayed, sorting them again when the order uses them. The children of
+// collapsed directories keep their cached infos until they are expanded.
+func (n *treeNode) refreshInfos(order sortOrder) {
+	if !order.usesInfo() {
+		return
+	}
+	stale := false
+	for _, child := range n.children {
+		stale = stale || child.stale
+		child.refreshInfo()
+		if child.expanded {
+			child.refreshInfos(order)
+		}
+	}
+	if stale {
+		sort.SliceStable(n.children, func(i, j int) bool {
+			return order.less(n.children[i].entry, n.children[j].entry)
+		})
+	}
+}
+
+// find returns the loaded node in the subtree with the given path, or nil if it is not loaded.
+func (n *treeNode) find(path string) *treeNode {
+	rel, err := filepath.Rel(n.fullPath, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	node := n
+	if rel == "." {
+		return node
+	}
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		var next *treeNode
+		for _, child := range node.children {
+			if child.entry.Name() == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// sortChildren re-sorts all loaded children in the subtree using the provided order
+func (n *treeNode) sortChildren(order sortOrder) {
+	sort.SliceStable(n.children, func(i, j int) bool {
+		return order.less(n.children[i].entry, n.children[j].entry)
+	})
+	for _, child := range n.children {
+		if child.loaded {
+			child.sortChildren(order)
+		}
+	}
+}
+
 // isLastChild returns true if this node is the last visible child of its parent
-func (n *treeNode) isLastChild(modeHidden bool) bool {
+func (n *treeNode) isLastChild(filter nodeFilter) bool {
 	if n.parent == nil {
 		return false // Root has no parent
 	}
@@ -70,10 +258,7 @@ func (n *treeNode) isLastChild(modeHidden bool) bool {
 	// Find the last visible sibling
 	for i := len(n.parent.children) - 1; i >= 0; i-- {
 		sibling := n.parent.children[i]
-		if sibling.entry == nil {
-			continue
-		}
-		if !modeHidden && sibling.entry.hasMode(entryModeHidden) {
+		if sibling.entry == nil || filter.skip(sibling) {
 			continue
 		}
 		return sibling == n
@@ -82,15 +267,15 @@ func (n *treeNode) isLastChild(modeHidden bool) bool {
 }
 
 // flatten returns visible nodes in DFS order (only expanded subtrees)
-func (n *treeNode) flatten(modeHidden bool) []*treeNode {
+func (n *treeNode) flatten(filter nodeFilter) []*treeNode {
 	var nodes []*treeNode
-	n.flattenInto(&nodes, modeHidden)
+	n.flattenInto(&nodes, filter)
 	return nodes
 }
 
-func (n *treeNode) flattenInto(nodes *[]*treeNode, modeHidden bool) {
-	// Skip hidden unless mode is on
-	if n.entry != nil && !modeHidden && n.entry.hasMode(entryModeHidden) {
+func (n *treeNode) flattenInto(nodes *[]*treeNode, filter nodeFilter) {
+	// Skip hidden and ignored unless shown
+	if filter.skip(n) {
 		return
 	}
 
@@ -98,43 +283,43 @@ func (n *treeNode) flattenInto(nodes *[]*treeNode, modeHidden bool) {
 
 	if n.expanded && n.loaded {
 		for _, child := range n.children {
-			child.flattenInto(nodes, modeHidden)
+			child.flattenInto(nodes, filter)
 		}
 	}
 }
 
 // loadAllDescendants recursively loads entire subtree from disk
-func (n *treeNode) loadAllDescendants() error {
-	if err := n.loadChildren(); err != nil {
+func (n *treeNode) loadAllDescendants(order sortOrder) error {
+	if err := n.loadChildren(order); err != nil {
 		return err
 	}
 	for _, child := range n.children {
 		if child.entry != nil && child.entry.hasMode(entryModeDir) {
 			// Ignore errors for unreadable directories to continue searching
-			_ = child.loadAllDescendants()
+			_ = child.loadAllDescendants(order)
 		}
 	}
 	return nil
 }
 
 // collectAllDescendants collects all descendants into a flat list regardless of expanded state
-func (n *treeNode) collectAllDescendants(modeHidden bool) []*treeNode {
+func (n *treeNode) collectAllDescendants(filter nodeFilter, order sortOrder) []*treeNode {
 	if n == nil {
 		return nil
 	}
 	var nodes []*treeNode
-	n.collectAllDescendantsInto(&nodes, modeHidden)
+	n.collectAllDescendantsInto(&nodes, filter, order)
 	return nodes
 }
 
-func (n *treeNode) collectAllDescendantsInto(nodes *[]*treeNode, modeHidden bool) {
+func (n *treeNode) collectAllDescendantsInto(nodes *[]*treeNode, filter nodeFilter, order sortOrder) {
 	// Skip nil nodes
 	if n == nil {
 		return
 	}
 
-	// Skip hidden unless mode is on
-	if n.entry != nil && !modeHidden && n.entry.hasMode(entryModeHidden) {
+	// Skip hidden and ignored unless shown
+	if filter.skip(n) {
 		return
 	}
 
@@ -145,28 +330,54 @@ func (n *treeNode) collectAllDescendantsInto(nodes *[]*treeNode, modeHidden bool
 	if n.entry != nil && n.entry.hasMode(entryModeDir) {
 		// Load children if not already loaded
 		if !n.loaded {
-			_ = n.loadChildren() // Ignore errors
+			_ = n.loadChildren(order) // Ignore errors
 		}
 		if n.children != nil {
 			for _, child := range n.children {
 				if child != nil {
-					child.collectAllDescendantsInto(nodes, modeHidden)
+					child.collectAllDescendantsInto(nodes, filter, order)
 				}
 			}
 		}
 	}
 }
 
+// collectDescendants collects the subtree in the order of collectAllDescendants, loading at most
+// limit nodes and returning false if the subtree has more.
+func (n *treeNode) collectDescendants(filter nodeFilter, order sortOrder, limit int) ([]*treeNode, bool) {
+	var nodes []*treeNode
+	stack := []*treeNode{n}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if filter.skip(node) {
+			continue
+		}
+		if len(nodes) == limit {
+			return nodes, false
+		}
+		nodes = append(nodes, node)
+
+		if node.entry.hasMode(entryModeDir) && !node.loaded {
+			_ = node.loadChildren(order) // Ignore errors
+		}
+		for i := len(node.children) - 1; i >= 0; i-- {
+			stack = append(stack, node.children[i])
+		}
+	}
+	return nodes, true
+}
+
 // searchSubtree performs recursive substring search in expanded subtrees
-func (n *treeNode) searchSubtree(query string, modeHidden bool) []*treeNode {
+func (n *treeNode) searchSubtree(query string, filter nodeFilter) []*treeNode {
 	var results []*treeNode
-	n.searchSubtreeInto(query, modeHidden, &results)
+	n.searchSubtreeInto(query, filter, &results)
 	return results
 }
 
-func (n *treeNode) searchSubtreeInto(query string, modeHidden bool, results *[]*treeNode) {
+func (n *treeNode) searchSubtreeInto(query string, filter nodeFilter, results *[]*treeNode) {
 	if n.entry != nil {
-		if !modeHidden && n.entry.hasMode(entryModeHidden) {
+		if filter.skip(n) {
 			return
 		}
 
@@ -178,14 +389,14 @@ func (n *treeNode) searchSubtreeInto(query string, modeHidden bool, results *[]*
 	// Search expanded children
 	if n.expanded && n.loaded {
 		for _, child := range n.children {
-			child.searchSubtreeInto(query, modeHidden, results)
+			child.searchSubtreeInto(query, filter, results)
 		}
 	}
 }
 
 // buildFilteredTree builds a tree showing only branches that lead to matching nodes
 // Returns a flattened list of nodes (matches + their ancestors) in DFS order
-func buildFilteredTree(root *treeNode, matches []*treeNode, modeHidden bool) []*treeNode {
+func buildFilteredTree(root *treeNode, matches []*treeNode, filter nodeFilter) []*treeNode {
 	if len(matches) == 0 {
 		return nil
 	}
@@ -203,11 +414,11 @@ func buildFilteredTree(root *treeNode, matches []*treeNode, modeHidden bool) []*
 
 	// Flatten the tree showing only included nodes
 	var result []*treeNode
-	buildFilteredTreeFlatten(root, includeSet, modeHidden, &result)
+	buildFilteredTreeFlatten(root, includeSet, filter, &result)
 	return result
 }
 
-func buildFilteredTreeFlatten(node *treeNode, includeSet map[*treeNode]bool, modeHidden bool, result *[]*treeNode) {
+func buildFilteredTreeFlatten(node *treeNode, includeSet map[*treeNode]bool, filter nodeFilter, result *[]*treeNode) {
 	// Skip if not in include set
 	if !includeSet[node] {
 		return
@@ -215,8 +426,8 @@ func buildFilteredTreeFlatten(node *treeNode, includeSet map[*treeNode]bool, mod
 
 	// Skip virtual root node (entry == nil) - it won't render anyway
 	if node.entry != nil {
-		// Skip hidden unless mode is on
-		if !modeHidden && node.entry.hasMode(entryModeHidden) {
+		// Skip hidden and ignored unless shown
+		if filter.skip(node) {
 			return
 		}
 
@@ -227,14 +438,14 @@ func buildFilteredTreeFlatten(node *treeNode, includeSet map[*treeNode]bool, mod
 	// Recursively process children that are in the include set
 	for _, child := range node.children {
 		if includeSet[child] {
-			buildFilteredTreeFlatten(child, includeSet, modeHidden, result)
+			buildFilteredTreeFlatten(child, includeSet, filter, result)
 		}
 	}
 }
 
-// streamDFS performs DFS traversal and sends batches of nodes to the channel.
-// It checks ctx.Done() periodically to allow cancellation.
-func streamDFS(ctx context.Context, root *treeNode, modeHidden bool, ch chan<- []*treeNode) {
+// streamDFS performs DFS traversal and sends batches of nodes to the channel, listing directories
+// from the cache where it has them. It checks ctx.Done() periodically to allow cancellation.
+func streamDFS(ctx context.Context, root *treeNode, filter nodeFilter, order sortOrder, cache *indexCache, ch chan<- []*treeNode) {
 	if root == nil {
 		return
 	}
@@ -259,15 +470,16 @@ func streamDFS(ctx context.Context, root *treeNode, modeHidden bool, ch chan<- [
 			continue
 		}
 
-		// Skip hidden if needed
-		if node.entry != nil && !modeHidden && node.entry.hasMode(entryModeHidden) {
+		// Skip hidden and ignored if needed
+		if filter.skip(node) {
 			continue
 		}
 
 		// Load children if directory
-		if node.entry != nil && node.entry.hasMode(entryModeDir) && !node.loaded {
-			_ = node.loadChildren() // Ignore errors
+		if node.entry != nil && node.entry.hasMode(entryModeDir) && !node.loaded && !cache.loadChildren(node, order) {
+			_ = node.loadChildren(order) // Ignore errors
 		}
+		cache.record(node)
 
 		// Add to batch (skip virtual root)
 		if node.entry != nil {
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// writeTestFiles creates empty files, with their parent directories, at the slash-separated paths
+// relative to dir.
+func writeTestFiles(t *testing.T, dir string, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// loadTestTree starts tree view mode in dir and runs the index loader to completion, so that the
+// tree is no longer walked concurrently. It returns the command that validates the index cache.
+func loadTestTree(t *testing.T, m *model, dir string) tea.Cmd {
+	t.Helper()
+	m.path = dir
+	m.modeTree = true
+	err, cmd := m.listTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		msg, ok := cmd().(searchIndexBatchMsg)
+		if !ok {
+			t.Fatalf("expected an index batch, got %T", msg)
+		}
+		_, cmd = m.update(msg)
+		if msg.done {
+			return cmd
+		}
+	}
+}
+
+// newTestTree returns a model in tree view mode, with its search index loaded, in a new directory
+// holding empty files at the slash-separated paths. The index is cached in a new directory.
+func newTestTree(t *testing.T, paths ...string) (*model, string) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	writeTestFiles(t, dir, paths...)
+	m := newModel()
+	loadTestTree(t, m, dir)
+	return m, dir
+}
+
+func TestRenderIgnoredTreeNode(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	writeTestFiles(t, dir, ".git/HEAD", "x.log")
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newModel()
+	m.modeIgnored = true
+	m.modeGit = false
+	loadTestTree(t, m, dir)
+	for i, node := range m.visibleNodes {
+		if node.entry.Name() != "x.log" {
+			continue
+		}
+		if !node.ignored {
+			t.Fatal("expected x.log to be ignored")
+		}
+
+		// Ignored entries are dimmed only with color.
+		m.modeColor = true
+		if line := m.renderTreeNode(node, i, m.displayNameOpts()); !strings.Contains(line, string(newColor("2"))) {
+			t.Fatalf("expected the ignored entry to be dimmed, got %q", line)
+		}
+		m.modeColor = false
+		if line := m.renderTreeNode(node, i, m.displayNameOpts()); strings.Contains(line, "\033") {
+			t.Fatalf("expected no escape sequences without color, got %q", line)
+		}
+		return
+	}
+	t.Fatal("expected x.log in the tree")
+}
@@ -12,10 +12,14 @@ func usage() string {
 	%s (%s) is a terminal filesystem explorer built for interactive ls workflows.
 	
 	Useful key commands are listed in the status bar.
+
+	Bookmarks are edited with "%s bookmarks add NAME [PATH]", "%s bookmarks rm NAME",
+	and "%s bookmarks ls".
 `
 
 	return fmt.Sprintf(usage,
 		name, getVersion(),
+		name, name, name,
 	)
 }
 
@@ -40,36 +44,88 @@ func commands() string {
 	------------
 
 	Arrow keys are used to move the cursor.
-	Vim navigation is available using "h" (left), "j" (down) "k" (up), and "l" (right).
-	In tree view mode, "h" collapses directories or goes up a level, "l" expands directories,
-	and "j"/"k" navigate through the visible tree.
+	Vim navigation is available using "%[1]s" (left), "%[2]s" (down) "%[3]s" (up), and "%[4]s" (right).
+	In tree view mode, "%[1]s" collapses directories or goes up a level, "%[4]s" expands directories,
+	and "%[2]s"/"%[3]s" navigate through the visible tree.
 
-%s
+%[5]s
 `
 	cmds := []string{
 		usageKeyLine("navigates into the directory or returns the\npath to the entry under the cursor", keySelect),
-		usageKeyLine("navigates back to the previous directory", keyBack),
+		usageKeyLine("navigates up to the parent directory", keyBack),
 		"",
 		usageKeyLine("returns the path(s) to the current entry or all marked entries", keyReturnSelected),
 		usageKeyLine("returns the path to the current directory", keyReturnDirectory),
 		"",
 		usageKeyLine("enters search mode (insert into the path)", keyModeSearch),
 		usageKeyLine("enters help mode", keyModeHelp),
+		usageKeyLine("cycles the search strategy (prefix, substring, smart-case, fuzzy)\nin normal mode search, tree view mode always matches fuzzily", keySearchStrategy),
+		usageKeyLine("toggles matching relative paths in tree view search mode", keySearchPaths),
+		usageKeyLine("toggles listing tree view search matches flat by relative path\nor as a filtered tree", keyToggleFlat),
 		usageKeyLine("switches back to normal mode or clears search filter in normal mode", keyEsc),
 		"",
 		usageKeyLine("(un)marks an entry for multiselect return", keyMark),
 		usageKeyLine("(un)marks all entries for multiselect return", keyMarkAll),
+		usageKeyLine("(un)marks an entry and all of its descendants in tree view mode", keyMarkSubtree),
+		usageKeyLine("opens or closes the basket of marked entries", keyModeBasket),
+		"",
+		usageKeyLine("renames the entry under the cursor", keyRename),
+		usageKeyLine("creates a new file", keyNewFile),
+		usageKeyLine("creates a new directory", keyNewDir),
+		usageKeyLine("copies the marked entries or the entry under the cursor", keyCopy),
+		usageKeyLine("moves the marked entries or the entry under the cursor", keyMove),
+		usageKeyLine("moves the marked entries or the entry under the cursor to the trash,\nor permanently deletes the selected item in the trash browser", keyDelete),
+		usageKeyLine("opens or closes the trash browser", keyModeTrash),
+		usageKeyLine("restores the selected item in the trash browser", keyRestore),
+		"",
+		usageKeyLine("bookmarks the directory under the cursor or the current directory", keyAddBookmark),
+		usageKeyLine("opens or closes the bookmark picker", keyModeBookmarks),
+		usageKeyLine("jumps to the Nth bookmark in the bookmark picker by pressing N (1-9)", keyJumpBookmark),
+		usageKeyLine("opens or closes the list of frecently visited directories", keyModeFrecency),
+		"",
+		usageKeyLine("goes back to the previous directory in the history", keyHistoryBack),
+		usageKeyLine("goes forward to the next directory in the history", keyHistoryForward),
+		usageKeyLine("opens or closes the history of visited directories", keyModeHistory),
+		"",
+		usageKeyLine("opens a new tab in the current directory", keyNewTab),
+		usageKeyLine("closes the current tab", keyCloseTab),
+		usageKeyLine("switches to the next tab", keyNextTab),
+		usageKeyLine("switches to the previous tab", keyPrevTab),
+		usageKeyLine("moves the current tab left", keyMoveTabLeft),
+		usageKeyLine("moves the current tab right", keyMoveTabRight),
+		"",
+		usageKeyLine("toggles dual-pane mode", keyToggleDualPane),
+		usageKeyLine("switches the focus to the other pane in dual-pane mode", keySwitchPane),
+		usageKeyLine("marks the entries that differ between the panes in dual-pane mode", keyComparePanes),
+		usageKeyLine("returns the paths to the directories of both panes in dual-pane mode", keyReturnPanes),
 		"",
 		usageKeyLine("toggles showing hidden files (ls -a)", keyToggleHidden),
+		usageKeyLine("toggles showing entries ignored by git in tree view mode", keyToggleIgnored),
 		usageKeyLine("toggles listing full file information (ls -l)", keyToggleList),
 		usageKeyLine("toggles following symlinks", keyToggleFollowSymlink),
 		usageKeyLine("toggles tree view mode", keyToggleTree),
+		usageKeyLine("toggles the preview pane", keyTogglePreview),
+		usageKeyLine("expands or collapses a directory in tree view mode", keyToggleExpand),
+		usageKeyLine("cycles the sort order (name, size, time, extension, natural)", keySort),
+		usageKeyLine("reverses the sort order", keySortReverse),
+		usageKeyLine("jumps to the bottom in tree view mode", keyGotoBottom),
+		usageKeyLine("jumps to the top in tree view mode (press twice)", keyGotoTop),
 		"",
 		usageKeyLine("dismisses errors", keyDismissError),
 		usageKeyLine("quits the application with no return value", keyQuit),
 	}
 
-	return fmt.Sprintf(usage, strings.Join(cmds, "\n"))
+	return fmt.Sprintf(usage,
+		lastKeyString(keyLeft), lastKeyString(keyDown), lastKeyString(keyUp), lastKeyString(keyRight),
+		strings.Join(cmds, "\n"),
+	)
+}
+
+// lastKeyString returns the last key of a binding, which is the vim-style alternative for the
+// default movement bindings.
+func lastKeyString(key key.Binding) string {
+	keys := key.Keys()
+	return keys[len(keys)-1]
 }
 
 func flags() string {
@@ -99,20 +155,63 @@ func flags() string {
 		usageFlagLine("display version", flagVersion, flagVersionShort),
 		"",
 		usageFlagLine("start in search mode", flagSearch, flagSearchShort),
+		usageFlagLine("match normal mode searches by prefix (default),\nsubstring, smart-case, or fuzzy (tree view mode\nalways matches fuzzily)", flagSearchStrategy),
+		usageFlagLine("match tree view searches against relative paths", flagSearchPaths),
+		usageFlagLine("list tree view search matches flat by relative path", flagFlat),
 		"",
 		usageFlagLine("return output suitable for pipe and subshell usage", flagPipe),
+		usageFlagLine("write returned paths as shell (escaped, default), print0\n(NUL-terminated), or json (path, type, size, mtime, key)", flagOutput),
+		usageFlagLine("write returned paths terminated by NUL bytes, the\nsame as --output print0", flagPrint0),
+		usageFlagLine("quote returned paths for none, posix (default), bash,\nzsh, fish, or powershell", flagQuote),
 		"",
 		usageFlagLine("toggle on following symlinks at startup", flagFollowSymlinks, flagFollowSymlinksShort),
 		usageFlagLine("toggle on showing hidden files at startup", flagHidden, flagHiddenShort),
+		usageFlagLine("toggle on showing entries ignored by git at startup", flagShowIgnored),
 		usageFlagLine("toggle on list mode at startup", flagList, flagListShort),
+		usageFlagLine("toggle on the preview pane at startup", flagPreview),
+		usageFlagLine("start in the directory of the named bookmark", flagBookmark),
+		usageFlagLine("start in the highest ranked visited directory matching\nthe following keywords, up to the next flag", flagJump),
 		"",
 		usageFlagLine("toggle off color output", flagNoColor),
 		usageFlagLine("toggle off bottom status bar menu", flagNoStatusBar),
 		usageFlagLine("toggle off trailing annotators", flagNoTrailing),
+		usageFlagLine("toggle off git status annotations", flagNoGit),
+		usageFlagLine("toggle off refreshing entries when the listed\ndirectories change", flagNoWatch),
+		usageFlagLine("use a built-in theme (dark, light) or a theme file\nfrom $XDG_CONFIG_HOME/nav/themes/<name>.toml", flagTheme),
 		"",
 		usageFlagLine("start in tree view mode", flagTree, flagTreeShort),
+		usageFlagLine("rebuild the cached tree view search index instead\nof loading it", flagRebuildIndex),
+		usageFlagLine("toggle off caching the tree view search index on disk", flagNoIndexCache),
+		"",
+		usageFlagLine("sort by name, size, time, extension, or natural\n(version) order", flagSort),
+		usageFlagLine("sort by size, largest first", flagSortSizeShort),
+		usageFlagLine("sort by extension", flagSortExtensionShort),
+		usageFlagLine("reverse the sort order", flagReverse, flagReverseShort),
+		usageFlagLine("ignore case when sorting by name", flagIgnoreCase),
+		usageFlagLine("sort directories together with files", flagNoDirsFirst),
+		"",
+		usageFlagLine("toggle off following symlinks at startup", flagNoFollowSymlinks),
+		usageFlagLine("toggle off showing hidden files at startup", flagNoHidden),
+		usageFlagLine("toggle off showing entries ignored by git at startup", flagNoShowIgnored),
+		usageFlagLine("toggle off list mode at startup", flagNoList),
+		usageFlagLine("toggle off the preview pane at startup", flagNoPreview),
+		usageFlagLine("start in the grid rather than tree view mode", flagNoTree),
+		usageFlagLine("match tree view searches against names", flagNoSearchPaths),
+		usageFlagLine("list tree view search matches as a filtered tree", flagNoFlat),
+		usageFlagLine("do not reverse the sort order", flagNoReverse),
+		usageFlagLine("respect case when sorting by name", flagNoIgnoreCase),
+		usageFlagLine("sort directories before files", flagDirsFirst),
+		usageFlagLine("toggle on color output", flagColor),
+		usageFlagLine("toggle on bottom status bar menu", flagStatusBar),
+		usageFlagLine("toggle on trailing annotators", flagTrailing),
+		usageFlagLine("toggle on git status annotations", flagGit),
+		usageFlagLine("toggle on refreshing entries when the listed\ndirectories change", flagWatch),
+		usageFlagLine("toggle on caching the tree view search index on disk", flagIndexCache),
 		"",
 		usageFlagLine("remap the escape key to the following value, using\nrepeated values to require multiple presses", flagRemapEsc),
+		usageFlagLine("bind an action to a comma-separated list of keys\nusing the form action=key[,key...], may be repeated", flagBind),
+		"",
+		usageFlagLine("do not load the config file ($NAV_CONFIG or\n$XDG_CONFIG_HOME/nav/config.toml)", flagNoConfig),
 	}
 	return fmt.Sprintf(usage, strings.Join(flags, "\n"))
 }
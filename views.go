@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -16,6 +17,7 @@ func (m *model) treeView() string {
 	}
 
 	viewHeight := m.height - 3 // Account for location bar (1) and status bar (2)
+	width := m.contentWidth()
 	displayNameOpts := m.displayNameOpts()
 
 	var output []string
@@ -52,8 +54,8 @@ func (m *model) treeView() string {
 		// Pad line to full terminal width to ensure consistent diff rendering
 		lineWidth := lipgloss.Width(rawLine)
 		paddedLine := rawLine
-		if lineWidth < m.width {
-			paddedLine = rawLine + strings.Repeat(" ", m.width-lineWidth)
+		if lineWidth < width {
+			paddedLine = rawLine + strings.Repeat(" ", width-lineWidth)
 		}
 
 		var finalLine string
@@ -82,11 +84,12 @@ func (m *model) treeView() string {
 	}
 
 	// Pad output to fill viewport height (prevents ghost lines from previous renders)
-	emptyLine := strings.Repeat(" ", m.width)
+	emptyLine := strings.Repeat(" ", width)
 	for len(output) < m.height-2 { // -2 for 2-line status bar
 		output = append(output, cursorRendererNormal.Render(emptyLine))
 	}
 
+	output = append(output[:1], m.joinPreview(output[1:])...)
 	return strings.Join(output, "\n")
 }
 
@@ -95,6 +98,9 @@ func (m *model) renderTreeNode(node *treeNode, idx int, opts []displayNameOption
 		// Virtual root - shouldn't happen in normal rendering
 		return ""
 	}
+	if m.flatSearchResults() {
+		return m.renderFlatSearchResult(node, opts)
+	}
 
 	// Helper to check if there are more visible siblings at a given depth level
 	// In DFS order, if we see another node at depth d before going back up (depth < d),
@@ -193,13 +199,50 @@ func (m *model) renderTreeNode(node *treeNode, idx int, opts []displayNameOption
 		indicator = "  " // align with dirs
 	}
 
+	if m.modeColor {
+		opts = append(opts[:len(opts):len(opts)], displayNameWithColor(m.entryColors, filepath.Dir(node.fullPath)))
+	}
+	if m.modeColor && node.ignored {
+		opts = append(opts[:len(opts):len(opts)], displayNameWithDim())
+	}
+	if m.modeGit && m.git.status != nil {
+		opts = append(opts[:len(opts):len(opts)], displayNameWithGitStatus(m.git.status, filepath.Dir(node.fullPath)))
+	}
+	if offsets, ok := m.searchHighlights[node]; ok && m.search != "" {
+		// Only the characters matched in the name are highlighted in the tree.
+		offsets = nameOffsets(offsets, searchPath(m.treeSearchRootPath(), node.fullPath), node.entry.Name())
+		opts = append(opts[:len(opts):len(opts)], displayNameWithHighlight(offsets, m.highlightStyle()))
+	}
 	name := newDisplayName(node.entry, opts...)
-	return prefix.String() + connector + indicator + name.String()
+	return treeRendererConnector.Render(prefix.String()+connector) + indicator + name.String()
+}
+
+// renderFlatSearchResult renders a search match in a flat list as its path relative to the search
+// root with the matched characters highlighted.
+func (m *model) renderFlatSearchResult(node *treeNode, opts []displayNameOption) string {
+	if m.modeColor {
+		opts = append(opts[:len(opts):len(opts)], displayNameWithColor(m.entryColors, filepath.Dir(node.fullPath)))
+	}
+	if m.modeColor && node.ignored {
+		opts = append(opts[:len(opts):len(opts)], displayNameWithDim())
+	}
+	if m.modeGit && m.git.status != nil {
+		opts = append(opts[:len(opts):len(opts)], displayNameWithGitStatus(m.git.status, filepath.Dir(node.fullPath)))
+	}
+	// Options using the name of the entry are applied before it is replaced by the path.
+	path := filepath.FromSlash(searchPath(m.treeSearchRootPath(), node.fullPath))
+	opts = append(opts[:len(opts):len(opts)], displayNameWithPath(path))
+	if offsets, ok := m.searchHighlights[node]; ok {
+		opts = append(opts, displayNameWithHighlight(offsets, m.highlightStyle()))
+	}
+	return newDisplayName(node.entry, opts...).String()
 }
 
 func (m *model) markedTreeNode(idx int) bool {
-	// In tree mode, marks are based on visible node index
-	_, marked := m.marks[idx]
+	if idx < 0 || idx >= len(m.visibleNodes) {
+		return false
+	}
+	_, marked := m.treeMarks[m.visibleNodes[idx].fullPath]
 	return marked
 }
 
@@ -212,23 +255,37 @@ func (m *model) normalView() string {
 		validEntries    = 0
 	)
 
-	// Construct display names from filtered entries and populate a new cache mapping between them.
+	// Filter hidden files.
+	entryIdxs := []int{}
+	names := []string{}
 	for entryIdx, ent := range m.entries {
-		// Filter hidden files.
 		if !m.modeHidden && ent.hasMode(entryModeHidden) {
 			continue
 		}
+		entryIdxs = append(entryIdxs, entryIdx)
+		names = append(names, ent.Name())
+	}
+	validEntries = len(entryIdxs)
 
-		validEntries++
+	// Filter for search, in the order of the matches.
+	matches := make([]searchMatch, 0, len(names))
+	if m.search != "" {
+		matches = matchSearchQuery(parseSearchQuery(m.search), m.searchStrategy, names, nil)
+	} else {
+		for i := range names {
+			matches = append(matches, searchMatch{index: i})
+		}
+	}
 
-		// Filter for search.
-		if m.search != "" {
-			if !strings.HasPrefix(ent.Name(), m.search) {
-				continue
-			}
+	// Construct display names from filtered entries and populate a new cache mapping between them.
+	for _, match := range matches {
+		entryIdx := entryIdxs[match.index]
+		opts := displayNameOpts
+		if len(match.matched) > 0 {
+			opts = append(opts[:len(opts):len(opts)], displayNameWithHighlight(match.matched, m.highlightStyle()))
 		}
 
-		displayNames = append(displayNames, newDisplayName(ent, displayNameOpts...))
+		displayNames = append(displayNames, newDisplayName(m.entries[entryIdx], opts...))
 		updateCache.addIndexPair(&indexPair{entry: entryIdx, display: displayed})
 		displayed++
 	}
@@ -245,7 +302,7 @@ func (m *model) normalView() string {
 
 	// Grid layout for display.
 	var (
-		width     = m.width
+		width     = m.contentWidth()
 		height    = m.height - 2 // Account for location and status bars.
 		gridNames [][]string
 		layout    gridLayout
@@ -259,8 +316,13 @@ func (m *model) normalView() string {
 	// Retrieve cached cursor position and index mappings to set cursor position for current state.
 	updateCursorPosition := &position{c: 0, r: 0}
 	if cache, found := m.pathCache[m.path]; found && cache.hasIndexes() {
-		// Lookup the entry index using the cached cursor (display) position.
-		if entryIdx, entryFound := cache.lookupEntryIndex(cache.cursorIndex()); entryFound {
+		// Lookup the entry index using the cached cursor entry name, falling back to the cached
+		// cursor (display) position.
+		entryIdx, entryFound := m.entryIndex(cache.cursorName)
+		if !entryFound {
+			entryIdx, entryFound = cache.lookupEntryIndex(cache.cursorIndex())
+		}
+		if entryFound {
 			// Use the entry index to get the current display index.
 			if dispIdx, dispFound := updateCache.lookupDisplayIndex(entryIdx); dispFound {
 				// Set the cursor position using the current display index and layout.
@@ -271,6 +333,9 @@ func (m *model) normalView() string {
 
 	// Update the cache.
 	updateCache.setPosition(updateCursorPosition)
+	if entryIdx, found := updateCache.lookupEntryIndex(updateCursorPosition.index(layout.rows)); found {
+		updateCache.setCursorName(m.entries[entryIdx].Name())
+	}
 	updateCache.setColumns(layout.columns)
 	updateCache.setRows(layout.rows)
 
@@ -283,9 +348,6 @@ func (m *model) normalView() string {
 	if m.c >= m.columns || m.r > m.rows {
 		m.resetCursor()
 	}
-	if err := m.reloadMarks(); err != nil {
-		m.setError(err, "failed to update marks")
-	}
 
 	// Render entry names in grid.
 	gridOutput := make([]string, layout.rows)
@@ -309,7 +371,7 @@ func (m *model) normalView() string {
 
 	// Construct the final view.
 	output := []string{m.locationBar()}
-	output = append(output, gridOutput...)
+	output = append(output, m.joinPreview(gridOutput)...)
 	return strings.Join(output, "\n")
 }
 
@@ -332,6 +394,64 @@ func (m *model) statusBar() string {
 			statusBarItem(fmt.Sprintf(`"%s": complete`, keyString(keyTab))),
 			statusBarItem(fmt.Sprintf(`"%s": normal mode`, keyString(keyEsc))),
 		}
+		if !m.modeTree {
+			mode = fmt.Sprintf("SEARCH (%s)", m.searchStrategy)
+			cmds = append(cmds, statusBarItem(fmt.Sprintf(`"%s": strategy`, keyString(keySearchStrategy))))
+		} else {
+			if m.modeSearchPaths {
+				mode = "SEARCH (paths)"
+			}
+			cmds = append(cmds,
+				statusBarItem(fmt.Sprintf(`"%s": paths`, keyString(keySearchPaths))),
+				statusBarItem(fmt.Sprintf(`"%s": flat`, keyString(keyToggleFlat))),
+			)
+		}
+	} else if m.modePrompt {
+		mode = "PROMPT"
+		cmds = []statusBarItem{
+			statusBarItem(fmt.Sprintf(`"%s": confirm`, keyString(keySelect))),
+			statusBarItem(fmt.Sprintf(`"%s": cancel`, keyString(keyEsc))),
+		}
+		if m.prompt.confirm {
+			cmds = []statusBarItem{
+				statusBarItem(`"y": confirm`),
+				statusBarItem(`any other key: cancel`),
+			}
+		}
+	} else if m.modeTrash {
+		mode = "TRASH"
+		cmds = []statusBarItem{
+			statusBarItem(fmt.Sprintf(`"%s": restore`, keyString(keyRestore))),
+			statusBarItem(fmt.Sprintf(`"%s": delete`, keyString(keyDelete))),
+			statusBarItem(fmt.Sprintf(`"%s": close`, keyString(keyEsc))),
+		}
+	} else if m.modeBookmarks {
+		mode = "BOOKMARKS"
+		cmds = []statusBarItem{
+			statusBarItem(fmt.Sprintf(`"%s": jump`, keyString(keySelect))),
+			statusBarItem(fmt.Sprintf(`"%s": remove`, keyString(keyDelete))),
+			statusBarItem(fmt.Sprintf(`"%s": close`, keyString(keyEsc))),
+		}
+	} else if m.modeFrecency {
+		mode = "FRECENT"
+		cmds = []statusBarItem{
+			statusBarItem(fmt.Sprintf(`"%s": jump`, keyString(keySelect))),
+			statusBarItem(fmt.Sprintf(`"%s": forget`, keyString(keyDelete))),
+			statusBarItem(fmt.Sprintf(`"%s": close`, keyString(keyEsc))),
+		}
+	} else if m.modeHistory {
+		mode = "HISTORY"
+		cmds = []statusBarItem{
+			statusBarItem(fmt.Sprintf(`"%s": go`, keyString(keySelect))),
+			statusBarItem(fmt.Sprintf(`"%s": close`, keyString(keyEsc))),
+		}
+	} else if m.modeBasket {
+		mode = "BASKET"
+		cmds = []statusBarItem{
+			statusBarItem(fmt.Sprintf(`"%s": go`, keyString(keySelect))),
+			statusBarItem(fmt.Sprintf(`"%s": remove`, keyString(keyDelete))),
+			statusBarItem(fmt.Sprintf(`"%s": close`, keyString(keyEsc))),
+		}
 	} else if m.modeHelp {
 		mode = "HELP"
 		cmds = []statusBarItem{
@@ -351,7 +471,7 @@ func (m *model) statusBar() string {
 		statusBarItem(fmt.Sprintf(`"%s": return dir`, keyString(keyReturnDirectory))),
 	}
 	if m.modeMarks {
-		globalCmds = append(globalCmds, statusBarItem(fmt.Sprintf(`"%s": return marked`, keyString(keyReturnSelected))))
+		globalCmds = append(globalCmds, statusBarItem(fmt.Sprintf(`"%s": return %d marked`, keyString(keyReturnSelected), len(m.markSet()))))
 	} else {
 		globalCmds = append(globalCmds, statusBarItem(fmt.Sprintf(`"%s": return cursor`, keyString(keyReturnSelected))))
 	}
@@ -367,6 +487,9 @@ func (m *model) statusBar() string {
 	gridItems := gridRowMajorFixedLayout(cmds, columns, rows)
 
 	nameAndMode := fmt.Sprintf(" %s   %s MODE  |", name, mode)
+	if order := m.sort.String(); order != "" {
+		nameAndMode = fmt.Sprintf(" %s   %s MODE   SORT: %s  |", name, mode, order)
+	}
 	output := strings.Join([]string{
 		barRendererStatus.Render(
 			fmt.Sprintf("%s\t%s\t",
@@ -395,6 +518,9 @@ func (m *model) locationBar() string {
 		)
 		return barRendererError.Render(err + "\t\t")
 	}
+	if m.modePrompt {
+		return m.promptBar()
+	}
 
 	locationBar := barRendererLocation.Render(m.location())
 	if m.modeSearch || m.search != "" {
@@ -402,7 +528,7 @@ func (m *model) locationBar() string {
 			locationBar += barRendererSearch.Render(fileSeparator + m.search)
 		}
 	}
-	return locationBar
+	return locationBar + m.gitBranchBar()
 }
 
 func (m *model) treeLocationBar() string {
@@ -415,6 +541,9 @@ func (m *model) treeLocationBar() string {
 		)
 		return barRendererError.Render(err + "\t\t")
 	}
+	if m.modePrompt {
+		return m.promptBar()
+	}
 
 	// In search mode, show parent context + search query instead of full path breadcrumb
 	if m.modeSearch || m.search != "" {
@@ -492,7 +621,7 @@ func (m *model) treeLocationBar() string {
 	breadcrumb := strings.Join(breadcrumbParts, "")
 
 	// Render with location bar background
-	return barRendererLocation.Render(breadcrumb)
+	return barRendererLocation.Render(breadcrumb) + m.gitBranchBar()
 }
 
 // treeSearchLocationBar renders the location bar during tree search mode
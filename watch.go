@@ -0,0 +1,220 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/dkaslovsky/nav/internal/watch"
+)
+
+const (
+	// watchDelay is the interval over which changes to the watched directories are collected
+	// before the entries are refreshed.
+	watchDelay = 100 * time.Millisecond
+
+	// watchIndexLimit is the maximum number of added nodes that are indexed when the watched
+	// directories change. The index loader is restarted to index more, so that reading large
+	// added directories does not block the interface.
+	watchIndexLimit = 1000
+)
+
+// watchMsg reports the directories whose entries changed.
+type watchMsg struct {
+	dirs []string
+}
+
+// startWatcher starts watching the listed directories. Watching is best effort: where it is not
+// supported the entries are refreshed only by navigation and file operations.
+func (m *model) startWatcher() tea.Cmd {
+	if !m.modeWatch {
+		return nil
+	}
+	w, err := watch.New(watchDelay)
+	if err != nil {
+		return nil
+	}
+	m.watcher = w
+	m.syncWatches()
+	return m.pollWatchCmd()
+}
+
+// stopWatcher stops watching the listed directories.
+func (m *model) stopWatcher() {
+	if m.watcher == nil {
+		return
+	}
+	_ = m.watcher.Close()
+	m.watcher = nil
+}
+
+// pollWatchCmd returns a command that waits for the next change to the watched directories.
+func (m *model) pollWatchCmd() tea.Cmd {
+	events := m.watcher.Events()
+	return func() tea.Msg {
+		dirs, ok := <-events
+		if !ok {
+			return nil
+		}
+		return watchMsg{dirs: dirs}
+	}
+}
+
+// syncWatches watches the directories that are listed: the current directory or, in tree view
+// mode, the root of the tree and every expanded directory. It is called wherever the listed
+// directories change.
+func (m *model) syncWatches() {
+	if m.watcher == nil {
+		return
+	}
+	dirs := []string{m.path}
+	if m.modeTree && m.treeRoot != nil {
+		dirs = m.treeRoot.expandedDirs(dirs[:0])
+	}
+	// Directories that cannot be watched, such as those removed since they were listed, are
+	// refreshed when their parent directory changes.
+	_ = m.watcher.Set(dirs)
+}
+
+// expandedDirs appends the paths of the node and its expanded descendants to dirs.
+func (n *treeNode) expandedDirs(dirs []string) []string {
+	dirs = append(dirs, n.fullPath)
+	for _, child := range n.children {
+		if child.expanded && child.loaded {
+			dirs = child.expandedDirs(dirs)
+		}
+	}
+	return dirs
+}
+
+// applyWatch refreshes the entries of the changed directories, keeping the cursor on the same
+// entry.
+func (m *model) applyWatch(dirs []string) tea.Cmd {
+	m.invalidateGitStatus()
+	if m.modeTree {
+		return m.refreshTreeDirs(dirs)
+	}
+
+	for _, dir := range dirs {
+		if dir == m.path {
+			// The cursor is restored by entry name from the cache when the view is rendered, and
+			// marks are keyed by path. A removed directory is reported once the user navigates.
+			m.saveCursor()
+			_ = m.list()
+			break
+		}
+	}
+	return nil
+}
+
+// refreshTreeDirs re-reads the children of the loaded tree directories and updates the search
+// index with the added and removed entries.
+func (m *model) refreshTreeDirs(dirs []string) tea.Cmd {
+	if m.treeRoot == nil {
+		return nil
+	}
+	// The index loader walks the tree concurrently, so the changes are applied once it is done.
+	if m.searchIndexLoading {
+		m.watchPending = append(m.watchPending, dirs...)
+		return nil
+	}
+	m.watchPending = nil
+
+	selected := m.selectedTreeNode()
+	prevIdx := m.treeIdx
+	searching := m.searchQueryChan != nil
+	if searching {
+		m.stopSearchWorker()
+	}
+
+	var added, removed []*treeNode
+	for _, dir := range dirs {
+		node := m.treeRoot.find(dir)
+		if node == nil {
+			continue
+		}
+		dirAdded, dirRemoved, err := node.reloadChildren(m.sort)
+		if err != nil {
+			// The directory itself was removed, which its parent reports.
+			continue
+		}
+		added, removed = append(added, dirAdded...), append(removed, dirRemoved...)
+	}
+
+	m.syncWatches()
+
+	// The restarted index loader starts the search worker again once it is done.
+	var cmd tea.Cmd
+	if m.searchIndexRoot == m.treeRoot && !m.updateSearchIndex(added, removed) {
+		searching = false
+		cmd = m.reindexTree()
+	} else {
+		m.rebuildVisibleNodes()
+	}
+	for i, node := range m.visibleNodes {
+		if node == selected {
+			m.treeIdx = i
+			// Keep the cursor on the same row of the screen.
+			m.scrollOffset = max(0, m.scrollOffset+i-prevIdx)
+			break
+		}
+	}
+	m.adjustScrollOffset()
+
+	if !searching {
+		return cmd
+	}
+	cmd = m.startSearchWorker()
+	if m.search != "" {
+		select {
+		case m.searchQueryChan <- m.search:
+		default:
+		}
+	}
+	return cmd
+}
+
+// updateSearchIndex removes the removed nodes and their descendants from the search index and adds
+// the added nodes and their descendants, returning false without adding them if there are more
+// than watchIndexLimit.
+func (m *model) updateSearchIndex(added []*treeNode, removed []*treeNode) bool {
+	if len(removed) != 0 {
+		gone := make(map[*treeNode]bool, len(removed))
+		for _, node := range removed {
+			gone[node] = true
+		}
+		nodes := make([]*treeNode, 0, len(m.searchIndexNodes))
+		names := make([]string, 0, len(m.searchIndexNames))
+		for i, node := range m.searchIndexNodes {
+			if !removedNode(node, gone) {
+				nodes = append(nodes, node)
+				names = append(names, m.searchIndexNames[i])
+			}
+		}
+		m.searchIndexNodes, m.searchIndexNames = nodes, names
+	}
+
+	var nodes []*treeNode
+	for _, node := range added {
+		descendants, ok := node.collectDescendants(m.nodeFilter(), m.sort, watchIndexLimit-len(nodes))
+		if !ok {
+			return false
+		}
+		nodes = append(nodes, descendants...)
+	}
+	for _, node := range nodes {
+		m.searchIndexNodes = append(m.searchIndexNodes, node)
+		m.searchIndexNames = append(m.searchIndexNames, node.entry.Name())
+	}
+	return true
+}
+
+// removedNode returns true if the node or one of its ancestors is in removed.
+func removedNode(node *treeNode, removed map[*treeNode]bool) bool {
+	for n := node; n != nil; n = n.parent {
+		if removed[n] {
+			return true
+		}
+	}
+	return false
+}
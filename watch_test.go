@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestApplyWatch(t *testing.T) {
+	m, dir := newTestTree(t, "a/x", "b/y", "c")
+	m.treeIdx = 0
+	m.treeToggleExpand()
+
+	// The cursor stays on c when entries are added above it.
+	m.treeIdx = 3
+	if name := m.selectedTreeNode().entry.Name(); name != "c" {
+		t.Fatalf("expected the cursor on c, got %s", name)
+	}
+	writeTestFiles(t, dir, "a/w", "0/z")
+	if err := os.RemoveAll(filepath.Join(dir, "b")); err != nil {
+		t.Fatal(err)
+	}
+	m.applyWatch([]string{dir, filepath.Join(dir, "a")})
+
+	visible := []string{}
+	for _, node := range m.visibleNodes {
+		visible = append(visible, node.entry.Name())
+	}
+	if want := []string{"0", "a", "w", "x", "c"}; !reflect.DeepEqual(visible, want) {
+		t.Fatalf("expected visible nodes %v, got %v", want, visible)
+	}
+	if name := m.selectedTreeNode().entry.Name(); name != "c" {
+		t.Fatalf("expected the cursor to stay on c, got %s", name)
+	}
+
+	// The index holds the added entries, with their descendants, but not the removed entries.
+	indexed := append([]string{}, m.searchIndexNames...)
+	sort.Strings(indexed)
+	if want := []string{"0", "a", "c", "w", "x", "z"}; !reflect.DeepEqual(indexed, want) {
+		t.Fatalf("expected indexed names %v, got %v", want, indexed)
+	}
+
+	// Changes arriving while the index is loading wait for the loader to finish.
+	m.searchIndexLoading = true
+	writeTestFiles(t, dir, "d")
+	m.applyWatch([]string{dir})
+	if len(m.visibleNodes) != 5 || !reflect.DeepEqual(m.watchPending, []string{dir}) {
+		t.Fatalf("expected the change to be pending, got %v", m.watchPending)
+	}
+	m.searchIndexLoading = false
+	m.refreshTreeDirs(m.watchPending)
+	if len(m.visibleNodes) != 6 || m.watchPending != nil {
+		t.Fatalf("expected the pending change to be applied, got %d visible nodes", len(m.visibleNodes))
+	}
+
+	// The grid is re-read when the current directory changes.
+	m.modeTree = false
+	if err := m.list(); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFiles(t, dir, "e")
+	m.applyWatch([]string{dir})
+	if len(m.entries) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(m.entries))
+	}
+}
+
+func TestApplyWatchStoppedLoader(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	for i := 0; i < searchBatchSize; i++ {
+		if err := os.MkdirAll(filepath.Join(dir, "a", strconv.Itoa(i), "b"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := newModel()
+	m.path = dir
+	m.modeTree = true
+	if err := m.loadTree(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The loader has exited once it is stopped while walking the tree, so that changes are applied
+	// to the tree at once.
+	if msg, ok := m.startSearchIndexLoader(m.treeRoot)().(searchIndexBatchMsg); !ok || msg.done {
+		t.Fatalf("expected a first batch of the index, got %v", msg)
+	}
+	m.stopSearchIndexLoader()
+	if err := os.Mkdir(filepath.Join(dir, "c"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m.applyWatch([]string{dir, filepath.Join(dir, "a")})
+	if m.watchPending != nil || len(m.treeRoot.children) != 2 {
+		t.Fatalf("expected the change to be applied, got %d children", len(m.treeRoot.children))
+	}
+
+	// More added entries than are indexed at once are indexed by the restarted loader.
+	if err := os.Rename(filepath.Join(dir, "a"), filepath.Join(dir, "d")); err != nil {
+		t.Fatal(err)
+	}
+	if cmd := m.applyWatch([]string{dir}); cmd == nil || !m.searchIndexLoading {
+		t.Fatal("expected the index loader to be restarted")
+	}
+	m.stopSearchIndexLoader()
+}